@@ -0,0 +1,203 @@
+package codes
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrMalformedCodeDictionary = errors.New("Malformed code dictionary")
+)
+
+// Looks up the human readable description of a code
+type CodeDictionary interface {
+	Describe(code string) (string, bool)
+}
+
+// The names of the code and description columns of a code dictionary file
+var codeDictionaryColumns = map[string][]string{
+	"code":        {"CODE", "CONCEPTCODE"},
+	"description": {"DESCRIPTION", "LONGDESCRIPTION", "DESCRIPTOR", "LONGDESCRIPTOR", "SHORTDESCRIPTION", "DISPLAY", "NAME"},
+}
+
+// A CodeDictionary of the codes of one code system, which are normalized for the code system when they are loaded
+// and when they are looked up
+type CodeTable struct {
+	system       CodeSystem
+	descriptions map[string]string
+}
+
+func NewCodeTable(system CodeSystem, descriptions map[string]string) *CodeTable {
+	table := &CodeTable{system: system, descriptions: make(map[string]string, len(descriptions))}
+	for code, description := range descriptions {
+		table.descriptions[NormalizeCode(system, code)] = description
+	}
+	return table
+}
+
+func LoadCodeTableFile(path string, system CodeSystem) (*CodeTable, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return LoadCodeTable(file, system)
+}
+
+// LoadCodeTable reads a code dictionary in CSV or TSV form, such as a licensed file of CPT descriptors. The first line
+// must be a header naming the code and description columns; the delimiter is detected from the header.
+func LoadCodeTable(reader io.Reader, system CodeSystem) (*CodeTable, error) {
+	records, err := newDelimitedReader(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	columnNames, err := records.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: missing header", ErrMalformedCodeDictionary)
+	} else if err != nil {
+		return nil, err
+	}
+
+	columns := columnIndexes(columnNames, codeDictionaryColumns)
+	if columns["code"] < 0 || columns["description"] < 0 {
+		return nil, fmt.Errorf("%w: header must name the code and description columns", ErrMalformedCodeDictionary)
+	}
+
+	table := &CodeTable{system: system, descriptions: make(map[string]string)}
+	for row := 1; ; row++ {
+		record, err := records.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		field := func(column string) string {
+			if index := columns[column]; index < len(record) {
+				return strings.TrimSpace(record[index])
+			}
+			return ""
+		}
+
+		code, description := field("code"), field("description")
+		if code == "" && description == "" {
+			continue
+		}
+		if code == "" {
+			return nil, fmt.Errorf("%w: row %d is missing the code", ErrMalformedCodeDictionary, row)
+		}
+		table.descriptions[NormalizeCode(system, code)] = description
+	}
+
+	return table, nil
+}
+
+func (t *CodeTable) System() CodeSystem {
+	return t.system
+}
+
+func (t *CodeTable) Describe(code string) (string, bool) {
+	description, ok := t.descriptions[NormalizeCode(t.system, code)]
+	return description, ok
+}
+
+// Codes returns the codes in the table in sorted order
+func (t *CodeTable) Codes() []string {
+	codes := make([]string, 0, len(t.descriptions))
+	for code := range t.descriptions {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+//go:embed reference/*.csv
+var referenceTableFiles embed.FS
+
+// The reference tables built in for code sets that are published without a license
+var referenceTablePaths = map[CodeSystem]string{
+	CODE_SYSTEM_PLACE_OF_SERVICE: "reference/place_of_service.csv",
+	CODE_SYSTEM_REVENUE:          "reference/revenue_codes.csv",
+	CODE_SYSTEM_DISCHARGE_STATUS: "reference/discharge_status.csv",
+}
+
+var (
+	referenceTables     = make(map[CodeSystem]*CodeTable)
+	referenceTablesLock sync.Mutex
+)
+
+// ReferenceCodeTable returns the built in table for place of service, revenue code or patient discharge status codes.
+// Tables are loaded on first use.
+func ReferenceCodeTable(system CodeSystem) (*CodeTable, bool) {
+	path, ok := referenceTablePaths[system]
+	if !ok {
+		return nil, false
+	}
+
+	referenceTablesLock.Lock()
+	defer referenceTablesLock.Unlock()
+	if table, ok := referenceTables[system]; ok {
+		return table, true
+	}
+
+	file, err := referenceTableFiles.Open(path)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	table, err := LoadCodeTable(file, system)
+	if err != nil {
+		panic(err)
+	}
+	referenceTables[system] = table
+	return table, true
+}
+
+// Describe writes the list like String, with the description of each code and range bound the dictionary knows
+// following it in parentheses: 0450..0459 (Emergency Room - General Classification .. Emergency Room - Other).
+func (cc *CodeList) Describe(dictionary CodeDictionary) string {
+	describe := func(code string) string {
+		if description, ok := dictionary.Describe(code); ok {
+			return description
+		}
+		return "?"
+	}
+
+	type entry struct{ key, text string }
+	entries := make([]entry, 0, len(cc.codes)+len(cc.codeRanges)+len(cc.patterns))
+	for code := range cc.codes {
+		text := code
+		if description, ok := dictionary.Describe(code); ok {
+			text += " (" + description + ")"
+		}
+		entries = append(entries, entry{code, text})
+	}
+	for _, cr := range cc.codeRanges {
+		key := cr.begin + ".." + cr.end
+		entries = append(entries, entry{key, key + " (" + describe(cr.begin) + " .. " + describe(cr.end) + ")"})
+	}
+	for _, pattern := range cc.patterns {
+		entries = append(entries, entry{pattern.text, pattern.text})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	texts := make([]string, len(entries))
+	for i, e := range entries {
+		texts[i] = e.text
+	}
+
+	except := ""
+	if cc.except != nil {
+		except = fmt.Sprintf(" EXCEPT [%s]", cc.except.Describe(dictionary))
+	}
+	return cc.keywords() + strings.Join(texts, ", ") + except
+}
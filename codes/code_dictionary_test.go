@@ -0,0 +1,88 @@
+package codes
+
+import (
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("Code Dictionaries", func() {
+
+	describe := func(table *CodeTable, code string) string {
+		description, ok := table.Describe(code)
+		Expect(ok).To(BeTrue(), code)
+		return description
+	}
+
+	Context("Reference tables", func() {
+		It("Describes place of service codes", func() {
+			table, ok := ReferenceCodeTable(CODE_SYSTEM_PLACE_OF_SERVICE)
+			Expect(ok).To(BeTrue())
+			Expect(table.System()).To(Equal(CODE_SYSTEM_PLACE_OF_SERVICE))
+			Expect(describe(table, "11")).To(Equal("Office"))
+			Expect(describe(table, "02")).To(Equal("Telehealth Provided Other than in Patient's Home"))
+			_, ok = table.Describe("98")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("Describes revenue codes", func() {
+			table, _ := ReferenceCodeTable(CODE_SYSTEM_REVENUE)
+			Expect(describe(table, "0450")).To(Equal("Emergency Room - General Classification"))
+		})
+
+		It("Describes discharge status codes", func() {
+			table, _ := ReferenceCodeTable(CODE_SYSTEM_DISCHARGE_STATUS)
+			Expect(describe(table, "20")).To(Equal("Expired"))
+			Expect(table.Codes()[0]).To(Equal("01"))
+		})
+
+		It("Loads each table once", func() {
+			first, _ := ReferenceCodeTable(CODE_SYSTEM_REVENUE)
+			second, _ := ReferenceCodeTable(CODE_SYSTEM_REVENUE)
+			Expect(first).To(BeIdenticalTo(second))
+		})
+
+		It("Has no tables for licensed code systems", func() {
+			_, ok := ReferenceCodeTable(CODE_SYSTEM_CPT)
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Context("Loading", func() {
+		It("Loads licensed dictionaries", func() {
+			file := "\ufeffConcept Code\tLong Descriptor\n99213\tOffice or other outpatient visit, established patient\n\n99214\tOffice visit, moderate\n"
+			table, err := LoadCodeTable(strings.NewReader(file), CODE_SYSTEM_CPT)
+			Expect(err).To(BeNil())
+			Expect(table.Codes()).To(Equal([]string{"99213", "99214"}))
+			Expect(describe(table, "99213-25")).To(Equal("Office or other outpatient visit, established patient"))
+		})
+
+		It("Requires code and description columns", func() {
+			_, err := LoadCodeTable(strings.NewReader("Code,Value\n1,2\n"), CODE_SYSTEM_CPT)
+			Expect(errors.Is(err, ErrMalformedCodeDictionary)).To(BeTrue())
+
+			_, err = LoadCodeTable(strings.NewReader("Code,Description\n,Orphan\n"), CODE_SYSTEM_CPT)
+			Expect(err).To(MatchError(ContainSubstring("row 1")))
+		})
+
+		It("Builds tables from maps", func() {
+			table := NewCodeTable(CODE_SYSTEM_ICD10_DIAG, map[string]string{"E11.9": "Type 2 diabetes mellitus without complications"})
+			Expect(describe(table, "E119")).To(Equal("Type 2 diabetes mellitus without complications"))
+		})
+	})
+
+	Context("Describing code lists", func() {
+		It("Adds descriptions to codes and ranges", func() {
+			table, _ := ReferenceCodeTable(CODE_SYSTEM_REVENUE)
+			list := ParseCodeListFor(CODE_SYSTEM_REVENUE, "0450..0459, 0762, 0998, 09* EXCEPT [0456]")
+			Expect(list.Describe(table)).To(Equal("0450..0459 (Emergency Room - General Classification .. Emergency Room - Other), " +
+				"0762 (Specialty Services - Observation Hours), 09*, 0998 EXCEPT [0456 (Emergency Room - Urgent Care)]"))
+		})
+
+		It("Accepts any CodeDictionary", func() {
+			var dictionary CodeDictionary = NewCodeTable(CODE_SYSTEM_PLACE_OF_SERVICE, map[string]string{"11": "Office"})
+			Expect(ParseCodeList("11, 12").WithStrictMatching().Describe(dictionary)).To(Equal("STRICT 11 (Office), 12"))
+		})
+	})
+})
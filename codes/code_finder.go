@@ -5,3 +5,20 @@ type CodeFinder interface {
 	HasAny(...string) bool
 	HasAll(...string) bool
 }
+
+// A code qualified by its code system, so that codes from different systems that are written the same way (a CPT code
+// and a revenue code, say) are never confused
+type CodedValue struct {
+	System CodeSystem
+	Code   string
+}
+
+func (cv CodedValue) String() string {
+	return string(cv.System) + ":" + cv.Code
+}
+
+// The code system aware CodeFinder, matching both the code system and the code
+type CodedValueFinder interface {
+	HasAny(...CodedValue) bool
+	HasAll(...CodedValue) bool
+}
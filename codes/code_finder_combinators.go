@@ -0,0 +1,138 @@
+package codes
+
+import (
+	"github.com/koanhealth/gotools/fixgo"
+	"github.com/koanhealth/gotools/sets"
+)
+
+// Combinators build a CodeFinder from others without copying their codes. Each finder is treated as the set of codes
+// it finds, so AnyOf is their union, AllOf their intersection and Not its complement.
+
+type anyOfFinder []CodeFinder
+
+// AnyOf finds the codes found by any of the finders
+func AnyOf(finders ...CodeFinder) CodeFinder {
+	return anyOfFinder(finders)
+}
+
+func (finders anyOfFinder) includes(code string) bool {
+	for _, finder := range finders {
+		if finder.HasAny(code) {
+			return true
+		}
+	}
+	return false
+}
+
+func (finders anyOfFinder) HasAny(codes ...string) bool {
+	for _, finder := range finders {
+		if finder.HasAny(codes...) {
+			return true
+		}
+	}
+	return false
+}
+
+func (finders anyOfFinder) HasAll(codes ...string) bool {
+	for _, code := range codes {
+		if !finders.includes(code) {
+			return false
+		}
+	}
+	return true
+}
+
+type allOfFinder []CodeFinder
+
+// AllOf finds the codes found by every one of the finders
+func AllOf(finders ...CodeFinder) CodeFinder {
+	return allOfFinder(finders)
+}
+
+func (finders allOfFinder) includes(code string) bool {
+	for _, finder := range finders {
+		if !finder.HasAny(code) {
+			return false
+		}
+	}
+	return true
+}
+
+func (finders allOfFinder) HasAny(codes ...string) bool {
+	for _, code := range codes {
+		if finders.includes(code) {
+			return true
+		}
+	}
+	return false
+}
+
+func (finders allOfFinder) HasAll(codes ...string) bool {
+	for _, finder := range finders {
+		if !finder.HasAll(codes...) {
+			return false
+		}
+	}
+	return true
+}
+
+type notFinder struct {
+	finder CodeFinder
+}
+
+// Not finds every code the finder does not, so Not(AnyOf(a, b)) finds the codes neither finds
+func Not(finder CodeFinder) CodeFinder {
+	return notFinder{finder: finder}
+}
+
+func (n notFinder) HasAny(codes ...string) bool {
+	return len(codes) > 0 && !n.finder.HasAll(codes...)
+}
+
+func (n notFinder) HasAll(codes ...string) bool {
+	return !n.finder.HasAny(codes...)
+}
+
+type normalizedFinder struct {
+	finder CodeFinder
+	system CodeSystem
+}
+
+// NormalizedFinder finds codes with the finder either as they are or normalized for the code system (see
+// NormalizeCode), so a finder holding normalized codes finds "e11.9" as well as "E119"
+func NormalizedFinder(finder CodeFinder, system CodeSystem) CodeFinder {
+	return normalizedFinder{finder: finder, system: system}
+}
+
+func (n normalizedFinder) includes(code string) bool {
+	return n.finder.HasAny(code, NormalizeCode(n.system, code))
+}
+
+func (n normalizedFinder) HasAny(codes ...string) bool {
+	for _, code := range codes {
+		if n.includes(code) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n normalizedFinder) HasAll(codes ...string) bool {
+	for _, code := range codes {
+		if !n.includes(code) {
+			return false
+		}
+	}
+	return true
+}
+
+// StringSetFinder adapts a set of codes, normalized for the code system, to a CodeFinder that normalizes the codes
+// it is asked about. Use CodeSystem "" for codes that are only upper-cased.
+func StringSetFinder(set sets.StringSet, system CodeSystem) CodeFinder {
+	return NormalizedFinder(set, system)
+}
+
+// SetFinder is StringSetFinder for a fixgo.Set
+func SetFinder(set fixgo.Set[string], system CodeSystem) CodeFinder {
+	return NormalizedFinder(set, system)
+}
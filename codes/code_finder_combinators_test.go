@@ -0,0 +1,103 @@
+package codes_test
+
+import (
+	"github.com/koanhealth/gotools/codes"
+	"github.com/koanhealth/gotools/fixgo"
+	"github.com/koanhealth/gotools/sets"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CodeFinder Combinators", func() {
+
+	diabetes := codes.ParseCodeList("E10..E11")
+	insulin := sets.NewStringSet("Z794")
+	exclusions := fixgo.NewSet("E10")
+
+	Context("AnyOf", func() {
+		finder := codes.AnyOf(diabetes, insulin)
+
+		It("Finds codes found by any finder", func() {
+			Expect(finder.HasAny("Z794")).To(BeTrue())
+			Expect(finder.HasAny("I10", "E11")).To(BeTrue())
+			Expect(finder.HasAny("I10")).To(BeFalse())
+		})
+
+		It("Needs each code found by some finder for HasAll", func() {
+			Expect(finder.HasAll("E11", "Z794")).To(BeTrue())
+			Expect(finder.HasAll("E11", "I10")).To(BeFalse())
+		})
+
+		It("Finds nothing without finders", func() {
+			Expect(codes.AnyOf().HasAny("E11")).To(BeFalse())
+		})
+	})
+
+	Context("AllOf", func() {
+		finder := codes.AllOf(diabetes, codes.Not(exclusions))
+
+		It("Finds codes found by every finder", func() {
+			Expect(finder.HasAny("E10", "E11")).To(BeTrue())
+			Expect(finder.HasAny("E10")).To(BeFalse())
+			Expect(finder.HasAny("Z794")).To(BeFalse())
+		})
+
+		It("Needs every code found by every finder for HasAll", func() {
+			Expect(finder.HasAll("E11")).To(BeTrue())
+			Expect(finder.HasAll("E11", "E10")).To(BeFalse())
+		})
+
+		It("Does not need one finder to find every code for HasAny", func() {
+			Expect(codes.AllOf(codes.AnyOf(diabetes, insulin), codes.Not(exclusions)).HasAny("E10", "Z794")).To(BeTrue())
+		})
+	})
+
+	Context("Not", func() {
+		finder := codes.Not(diabetes)
+
+		It("Finds codes the finder does not", func() {
+			Expect(finder.HasAny("E11")).To(BeFalse())
+			Expect(finder.HasAny("E11", "I10")).To(BeTrue())
+			Expect(finder.HasAll("I10", "Z794")).To(BeTrue())
+			Expect(finder.HasAll("I10", "E11")).To(BeFalse())
+		})
+
+		It("Finds nothing among no codes", func() {
+			Expect(finder.HasAny()).To(BeFalse())
+			Expect(finder.HasAll()).To(BeTrue())
+		})
+
+		It("Finds codes none of several finders do", func() {
+			Expect(codes.Not(codes.AnyOf(diabetes, insulin)).HasAll("I10")).To(BeTrue())
+			Expect(codes.Not(codes.AnyOf(diabetes, insulin)).HasAny("E11", "Z794")).To(BeFalse())
+		})
+
+		It("Is undone by another Not", func() {
+			Expect(codes.Not(finder).HasAny("E11")).To(BeTrue())
+			Expect(codes.Not(finder).HasAny("I10")).To(BeFalse())
+		})
+	})
+
+	Context("Adapters", func() {
+		It("Normalizes codes asked about a StringSet", func() {
+			finder := codes.StringSetFinder(sets.NewStringSet("E119", "Z794"), codes.CODE_SYSTEM_ICD10_DIAG)
+			Expect(finder.HasAll("e11.9", "Z79.4", "E119")).To(BeTrue())
+			Expect(finder.HasAny("E11.8")).To(BeFalse())
+		})
+
+		It("Normalizes codes asked about a fixgo Set", func() {
+			finder := codes.SetFinder(fixgo.NewSet("E119"), codes.CODE_SYSTEM_ICD10_DIAG)
+			Expect(finder.HasAny("E11.9")).To(BeTrue())
+			Expect(codes.SetFinder(fixgo.NewSet("A01"), "").HasAny("a01")).To(BeTrue())
+		})
+
+		It("Composes with code lists", func() {
+			eligible := codes.AllOf(
+				codes.AnyOf(diabetes, codes.StringSetFinder(insulin, codes.CODE_SYSTEM_ICD10_DIAG)),
+				codes.Not(codes.SetFinder(exclusions, "")),
+			)
+			Expect(eligible.HasAny("Z79.4")).To(BeTrue())
+			Expect(eligible.HasAny("e10")).To(BeFalse())
+		})
+	})
+})
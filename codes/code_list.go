@@ -5,8 +5,8 @@ import (
 	"fmt"
 	"strings"
 
+	kerrors "github.com/koanhealth/gotools/errors"
 	"github.com/koanhealth/gotools/slices"
-	"regexp"
 	"sort"
 )
 
@@ -15,17 +15,29 @@ var (
 	ErrInvalidCodeType   = errors.New("Invalid code type")
 	ErrInvalidCodeRange  = errors.New("Beginning and End of Code Range must have the same length")
 	ErrMalformedCodeList = errors.New("Malformed code list")
+	ErrMixedCodeSystems  = errors.New("Code lists of different code systems cannot be merged; use a SystemCodeList")
 )
 
 type CodeList struct {
-	codes       map[string]bool
-	codeRanges  []codeRange
-	strictMatch bool
-	except      *CodeList
+	codes          map[string]bool
+	codeRanges     []codeRange
+	patterns       []codePattern
+	strictMatch    bool
+	hierarchyMatch bool
+	except         *CodeList
+	system         CodeSystem
+	sources        map[string]codeListSource // Where each entry was written in the parsed text, keyed by entry
 }
 
+// String writes the list's entries in the grammar TryParseCodeList reads, so the entries can be parsed back from it.
+// Matching options are not written, so a strict or hierarchy list does not round trip through String; MarshalText
+// writes them as well.
 func (cc *CodeList) String() string {
-	keys := make([]string, 0, len(cc.codes)+len(cc.codeRanges))
+	return cc.text(false)
+}
+
+func (cc *CodeList) text(keywords bool) string {
+	keys := make([]string, 0, len(cc.codes)+len(cc.codeRanges)+len(cc.patterns))
 	for key := range cc.codes {
 		keys = append(keys, key)
 	}
@@ -34,14 +46,33 @@ func (cc *CodeList) String() string {
 		keys = append(keys, cr.begin+".."+cr.end)
 	}
 
+	for _, pattern := range cc.patterns {
+		keys = append(keys, pattern.text)
+	}
+
 	sort.Strings(keys)
 
 	except := ""
 	if cc.except != nil {
-		except = fmt.Sprintf(" EXCEPT [%s]", cc.except.String())
+		except = fmt.Sprintf(" EXCEPT [%s]", cc.except.text(keywords))
+	}
+
+	if !keywords {
+		return strings.Join(keys, ",") + except
 	}
+	return cc.keywords() + strings.Join(keys, ",") + except
+}
 
-	return strings.Join(keys, ",") + except
+// The keywords for the list's matching options, as they start the list in MarshalText
+func (cc *CodeList) keywords() string {
+	keywords := ""
+	if cc.strictMatch {
+		keywords = codeListKeywordStrict + " "
+	}
+	if cc.hierarchyMatch {
+		keywords += codeListKeywordHierarchy + " "
+	}
+	return keywords
 }
 
 func CompactCodes(minimumRangeLength int, codeStrings ...string) (result string, err error) {
@@ -156,6 +187,8 @@ func IncrementString(input string) string {
 	}
 }
 
+// ParseCodeList parses a code list with the compatible parser, panicking if it cannot. It reads back the entries
+// String writes but no matching options or comments; UnmarshalText reads what MarshalText writes, options and all.
 func ParseCodeList(codeList string) *CodeList {
 	cl, err := TryParseCodeList(codeList)
 	if err != nil {
@@ -165,41 +198,55 @@ func ParseCodeList(codeList string) *CodeList {
 }
 
 func TryParseCodeList(codeList string) (*CodeList, error) {
-	codeList = strings.TrimSpace(strings.ToUpper(codeList))
-	if codeList == "" {
-		return nil, ErrBlankCode
+	return TryParseCodeListFor("", codeList)
+}
+
+func ParseCodeListFor(system CodeSystem, codeList string) *CodeList {
+	cl, err := TryParseCodeListFor(system, codeList)
+	if err != nil {
+		panic(err)
 	}
+	return cl
+}
 
-	individualCodes := make(map[string]bool)
-	codeRanges := make([]codeRange, 0, 5)
+// TryParseCodeListFor parses a code list whose codes belong to the given code system. Each code and range bound is
+// normalized with NormalizeCode, as are the codes later passed to Includes, HasAny and HasAll. Hyphens are kept as
+// part of the code (as in LOINC or NDC codes) rather than treated as separators.
+func TryParseCodeListFor(system CodeSystem, codeList string) (*CodeList, error) {
+	list, errs := parseCodeList(codeList, system, codeListParseCompatible)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return list, nil
+}
 
-	splitter := regexp.MustCompile("[A-Za-z0-9.]+")
+// TryParseCodeListStrict parses a code list, rejecting anything that is not part of the code list grammar rather than
+// skipping it. The error is a *ParseError locating the first problem.
+func TryParseCodeListStrict(codeList string) (*CodeList, error) {
+	return TryParseCodeListStrictFor("", codeList)
+}
 
-	for _, code := range splitter.FindAllString(codeList, -1) {
-		rangeBounds := strings.Split(code, "..")
-		if len(rangeBounds) == 1 {
-			strippedCode := strings.TrimSpace(code)
-			if len(strippedCode) > 0 {
-				individualCodes[strippedCode] = true
-			}
-		} else if len(rangeBounds) == 2 {
-			strippedCode1 := strings.TrimSpace(rangeBounds[0])
-			strippedCode2 := strings.TrimSpace(rangeBounds[1])
-
-			if len(strippedCode1) > 0 && len(strippedCode2) > 0 {
-				newRange, err := newCodeRange(strippedCode1, strippedCode2)
-				if err != nil {
-					return nil, err
-				}
-				codeRanges = append(codeRanges, newRange)
-			} else {
-				return nil, ErrMalformedCodeList
-			}
-		} else {
-			return nil, ErrMalformedCodeList
-		}
+func TryParseCodeListStrictFor(system CodeSystem, codeList string) (*CodeList, error) {
+	list, errs := parseCodeList(codeList, system, codeListParseStrict)
+	if len(errs) > 0 {
+		return nil, errs[0]
 	}
-	return &CodeList{codes: individualCodes, codeRanges: codeRanges}, nil
+	return list, nil
+}
+
+// ParseCodeListLenient parses as much of a code list as it can, skipping the parts TryParseCodeListStrict would
+// reject. Every problem is returned as a *ParseError; the list is nil only when the input is blank.
+func ParseCodeListLenient(codeList string) (*CodeList, kerrors.ErrorSlice) {
+	return ParseCodeListLenientFor("", codeList)
+}
+
+func ParseCodeListLenientFor(system CodeSystem, codeList string) (*CodeList, kerrors.ErrorSlice) {
+	return parseCodeList(codeList, system, codeListParseLenient)
+}
+
+// System returns the code system the list was parsed for, or "" when codes are matched without normalization
+func (cc *CodeList) System() CodeSystem {
+	return cc.system
 }
 
 func (cc *CodeList) WithStrictMatching() *CodeList {
@@ -207,7 +254,32 @@ func (cc *CodeList) WithStrictMatching() *CodeList {
 	return cc
 }
 
+// WithHierarchyMatching makes the list's ICD-10-CM codes match their descendants as well, so E11 matches E11.65.
+// The except list then excludes descendants too, so E11 EXCEPT [E11.5] does not match E11.52.
+func (cc *CodeList) WithHierarchyMatching() *CodeList {
+	cc.hierarchyMatch = true
+	return cc
+}
+
+// Merge returns a list of the codes of both lists. A list without a code system takes on the other's. Lists of two
+// different code systems normalize codes differently, so no one list matches what both do: Merge panics with
+// ErrMixedCodeSystems for them, and a SystemCodeList holds a list for each code system instead.
 func (cc *CodeList) Merge(other *CodeList) *CodeList {
+	merged, err := cc.TryMerge(other)
+	if err != nil {
+		panic(err)
+	}
+	return merged
+}
+
+// TryMerge is Merge, returning ErrMixedCodeSystems for lists of two different code systems
+func (cc *CodeList) TryMerge(other *CodeList) (*CodeList, error) {
+	if cc.system != "" && other.system != "" && cc.system != other.system {
+		return nil, ErrMixedCodeSystems
+	}
+	system := mergedSystem(cc, other)
+	cc, other = cc.forSystem(system), other.forSystem(system)
+
 	individualCodes := make(map[string]bool, len(cc.codes)+len(other.codes))
 	codeRanges := make([]codeRange, 0, len(cc.codeRanges)+len(other.codeRanges))
 
@@ -220,12 +292,30 @@ func (cc *CodeList) Merge(other *CodeList) *CodeList {
 
 	codeRanges = append(codeRanges, cc.codeRanges...)
 	codeRanges = append(codeRanges, other.codeRanges...)
-	return &CodeList{codes: individualCodes, codeRanges: codeRanges, strictMatch: cc.strictMatch || other.strictMatch}
+	patterns := mergeCodePatterns(cc.patterns, other.patterns)
+	return &CodeList{codes: individualCodes, codeRanges: codeRanges, patterns: patterns, strictMatch: cc.strictMatch || other.strictMatch,
+		hierarchyMatch: cc.hierarchyMatch || other.hierarchyMatch, system: system, sources: mergedSources(cc, other)}, nil
+}
+
+func mergedSystem(cc, other *CodeList) CodeSystem {
+	switch {
+	case cc.system == "":
+		return other.system
+	case other.system == "" || other.system == cc.system:
+		return cc.system
+	default:
+		return ""
+	}
 }
 
+// Except returns a list of the codes of the receiver that are not in the other list. The other list is matched as a
+// list of the receiver's code system, so ParseCodeListFor(CODE_SYSTEM_ICD10_DIAG, "E11.0..E11.9") without "E11.5"
+// excludes E115. A list without a code system keeps its except lists' systems, and like Merge panics with
+// ErrMixedCodeSystems when they differ.
 func (cc *CodeList) Except(other *CodeList) *CodeList {
 	result := cc.copy()
 
+	other = other.forSystem(cc.system)
 	if cc.except != nil {
 		result.except = cc.except.Merge(other)
 	} else {
@@ -235,6 +325,51 @@ func (cc *CodeList) Except(other *CodeList) *CodeList {
 	return result
 }
 
+// Returns the list with its entries normalized for another code system. Lists are returned as they are for their own
+// code system and for "", since entries normalized for a code system are already upper-cased.
+func (cc *CodeList) forSystem(system CodeSystem) *CodeList {
+	if system == "" || system == cc.system {
+		return cc
+	}
+
+	result := &CodeList{codes: make(map[string]bool, len(cc.codes)), codeRanges: make([]codeRange, 0, len(cc.codeRanges)),
+		strictMatch: cc.strictMatch, hierarchyMatch: cc.hierarchyMatch, system: system}
+	entries := make(map[string]string, len(cc.sources))
+	for code := range cc.codes {
+		normalized := NormalizeCode(system, code)
+		result.codes[normalized] = true
+		entries[code] = normalized
+	}
+	for _, cr := range cc.codeRanges {
+		if normalized, err := newCodeRange(NormalizeCode(system, cr.begin), NormalizeCode(system, cr.end)); err == nil {
+			entries[cr.begin+".."+cr.end] = normalized.begin + ".." + normalized.end
+			cr = normalized
+		}
+		result.codeRanges = append(result.codeRanges, cr)
+	}
+	for _, pattern := range cc.patterns {
+		if normalized, err := newCodePattern(system, pattern.text); err == nil {
+			entries[pattern.text] = normalized.text
+			pattern = normalized
+		}
+		result.patterns = mergeCodePatterns(result.patterns, []codePattern{pattern})
+	}
+
+	for entry, source := range cc.sources {
+		if normalized, ok := entries[entry]; ok {
+			entry = normalized
+		}
+		if result.sources == nil {
+			result.sources = make(map[string]codeListSource, len(cc.sources))
+		}
+		result.sources[entry] = source
+	}
+	if cc.except != nil {
+		result.except = cc.except.forSystem(system)
+	}
+	return result
+}
+
 func (cc *CodeList) copy() *CodeList {
 	individualCodes := make(map[string]bool, len(cc.codes))
 	codeRanges := make([]codeRange, 0, len(cc.codeRanges))
@@ -244,15 +379,37 @@ func (cc *CodeList) copy() *CodeList {
 	}
 	codeRanges = append(codeRanges, cc.codeRanges...)
 
-	return &CodeList{codes: individualCodes, codeRanges: codeRanges, strictMatch: cc.strictMatch}
+	patterns := append([]codePattern(nil), cc.patterns...)
+	return &CodeList{codes: individualCodes, codeRanges: codeRanges, patterns: patterns, strictMatch: cc.strictMatch,
+		hierarchyMatch: cc.hierarchyMatch, system: cc.system, sources: cc.copySources()}
 }
 
 func (cc *CodeList) Includes(code string) bool {
-	if cc.except != nil && cc.except.Includes(code) {
+	return cc.includes(code, false)
+}
+
+// Includes for a list that matches descendants as well if it or a list it is the except list of uses hierarchy matching
+func (cc *CodeList) includes(code string, hierarchy bool) bool {
+	code = NormalizeCode(cc.system, code)
+	hierarchy = hierarchy || cc.hierarchyMatch
+	if cc.except != nil && cc.except.includes(code, hierarchy) {
 		return false
 	}
 
-	code = strings.ToUpper(code)
+	if cc.includesNormalized(code) {
+		return true
+	}
+	if hierarchy {
+		for _, ancestor := range icd10cmAncestorCodes(cc.system, code) {
+			if cc.includesNormalized(ancestor) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (cc *CodeList) includesNormalized(code string) bool {
 	_, present := cc.codes[code]
 	if present {
 		return true
@@ -262,6 +419,11 @@ func (cc *CodeList) Includes(code string) bool {
 				return true
 			}
 		}
+		for _, pattern := range cc.patterns {
+			if pattern.matches(code) {
+				return true
+			}
+		}
 	}
 
 	return false
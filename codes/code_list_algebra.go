@@ -0,0 +1,547 @@
+package codes
+
+import (
+	"math/bits"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Set operations treat a CodeList as the codes Includes matches. Ranges compare codes character by character, so
+// E11.8..E12.1 holds E12.0 and A1..AZ holds AB, and unless the list is strict a range also holds the longer codes
+// that sort between its bounds (A0011 for A001..A010) and the shorter ones that sort after the start of its
+// beginning and no later than its end. A * pattern holds every code that starts with its base. The operations work
+// on the entries themselves, never expanding them, as boxes: the codes of a range of lengths whose characters at
+// each position come from a set, so E11* is the box of codes of three or more characters starting E, 1 and 1. Codes
+// are taken to be made of digits, upper case letters, dots and hyphens, so a ? stands for any of those. The
+// descendants a hierarchy list, or the except list of one, matches are not part of the model.
+
+// The characters codes are made of, as far as the set operations are concerned
+var codeCharacters = newCharSet("-.0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+
+// A set of characters, one bit for each byte
+type charSet [4]uint64
+
+func newCharSet(chars string) charSet {
+	var set charSet
+	for i := 0; i < len(chars); i++ {
+		set[chars[i]/64] |= 1 << (chars[i] % 64)
+	}
+	return set
+}
+
+// The characters from low to high, none when low is greater than high
+func charRange(low, high int) charSet {
+	var set charSet
+	for c := max(low, 0); c <= min(high, 255); c++ {
+		set[c/64] |= 1 << (c % 64)
+	}
+	return set
+}
+
+func (s charSet) contains(c byte) bool {
+	return s[c/64]&(1<<(c%64)) != 0
+}
+
+func (s charSet) intersect(other charSet) charSet {
+	return charSet{s[0] & other[0], s[1] & other[1], s[2] & other[2], s[3] & other[3]}
+}
+
+func (s charSet) without(other charSet) charSet {
+	return charSet{s[0] &^ other[0], s[1] &^ other[1], s[2] &^ other[2], s[3] &^ other[3]}
+}
+
+func (s charSet) isEmpty() bool {
+	return s == charSet{}
+}
+
+func (s charSet) isSubsetOf(other charSet) bool {
+	return s.without(other).isEmpty()
+}
+
+func (s charSet) size() int {
+	return bits.OnesCount64(s[0]) + bits.OnesCount64(s[1]) + bits.OnesCount64(s[2]) + bits.OnesCount64(s[3])
+}
+
+// The lowest character in a set that is not empty
+func (s charSet) first() byte {
+	for i, word := range s {
+		if word != 0 {
+			return byte(i*64 + bits.TrailingZeros64(word))
+		}
+	}
+	return 0
+}
+
+// The highest character in a set that is not empty
+func (s charSet) last() byte {
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] != 0 {
+			return byte(i*64 + 63 - bits.LeadingZeros64(s[i]))
+		}
+	}
+	return 0
+}
+
+func (s charSet) chars() []byte {
+	chars := make([]byte, 0, s.size())
+	for c := 0; c < 256; c++ {
+		if s.contains(byte(c)) {
+			chars = append(chars, byte(c))
+		}
+	}
+	return chars
+}
+
+// The codes from minLength to maxLength characters long whose character at each position is in the box's set for
+// that position. Positions past the box's own sets (which never outnumber minLength) take any character of the
+// universe the box is used with.
+type codeBox struct {
+	positions []charSet
+	minLength int
+	maxLength int
+}
+
+// The maxLength of a box of codes of any length from its minLength up
+const codeBoxUnbounded = -1
+
+func singleCodeBox(code string) codeBox {
+	positions := make([]charSet, len(code))
+	for i := range positions {
+		positions[i] = newCharSet(code[i : i+1])
+	}
+	return codeBox{positions: positions, minLength: len(code), maxLength: len(code)}
+}
+
+func (b codeBox) at(position int, universe charSet) charSet {
+	if position < len(b.positions) {
+		return b.positions[position]
+	}
+	return universe
+}
+
+func (b codeBox) isEmpty() bool {
+	if b.maxLength != codeBoxUnbounded && b.minLength > b.maxLength {
+		return true
+	}
+	for _, set := range b.positions {
+		if set.isEmpty() {
+			return true
+		}
+	}
+	return false
+}
+
+func shorterMaxLength(a, b int) int {
+	switch {
+	case a == codeBoxUnbounded:
+		return b
+	case b == codeBoxUnbounded:
+		return a
+	default:
+		return min(a, b)
+	}
+}
+
+func (b codeBox) intersect(other codeBox, universe charSet) codeBox {
+	positions := make([]charSet, max(len(b.positions), len(other.positions)))
+	for i := range positions {
+		positions[i] = b.at(i, universe).intersect(other.at(i, universe))
+	}
+	return codeBox{positions: positions, minLength: max(b.minLength, other.minLength), maxLength: shorterMaxLength(b.maxLength, other.maxLength)}
+}
+
+// The codes of the box that are not in the other box, as boxes that do not overlap
+func (b codeBox) subtract(other codeBox, universe charSet) []codeBox {
+	rest := b.intersect(other, universe)
+	if rest.isEmpty() {
+		return []codeBox{b}
+	}
+
+	pieces := make([]codeBox, 0)
+	if other.minLength > b.minLength {
+		pieces = append(pieces, codeBox{positions: b.positions, minLength: b.minLength, maxLength: other.minLength - 1})
+	}
+	if other.maxLength != codeBoxUnbounded && (b.maxLength == codeBoxUnbounded || b.maxLength > other.maxLength) {
+		pieces = append(pieces, codeBox{positions: b.positions, minLength: other.maxLength + 1, maxLength: b.maxLength})
+	}
+
+	// Of the codes with lengths both boxes allow, split off those outside the other box at each position in turn
+	for i := range rest.positions {
+		rest.positions[i] = b.at(i, universe)
+	}
+	for i := range rest.positions {
+		if outside := rest.positions[i].without(other.at(i, universe)); !outside.isEmpty() {
+			piece := codeBox{positions: append([]charSet(nil), rest.positions...), minLength: rest.minLength, maxLength: rest.maxLength}
+			piece.positions[i] = outside
+			pieces = append(pieces, piece)
+		}
+		rest.positions[i] = rest.positions[i].intersect(other.at(i, universe))
+	}
+	return pieces
+}
+
+// Bounds on the codes of the box: every one sorts from low to high
+func (b codeBox) bounds() (string, string) {
+	low, high := make([]byte, len(b.positions)), make([]byte, len(b.positions)+1)
+	for i, set := range b.positions {
+		low[i], high[i] = set.first(), set.last()
+	}
+	high[len(b.positions)] = 0xFF
+	return string(low), string(high)
+}
+
+// The boxes of the codes of the bounds' length that sort between them, with the characters at each position drawn
+// from its class. Each bound is in the interval or not as asked.
+func intervalBoxes(low, high string, withLow, withHigh bool, class func(position int) charSet) []codeBox {
+	if low > high || (low == high && !(withLow && withHigh)) {
+		return nil
+	}
+	if low == high {
+		return []codeBox{singleCodeBox(low)}
+	}
+
+	length := len(low)
+	boxes := make([]codeBox, 0)
+	add := func(prefix string, set charSet) {
+		box := singleCodeBox(prefix)
+		box.minLength, box.maxLength = length, length
+		box.positions = append(box.positions, set.intersect(class(len(prefix))))
+		for i := len(prefix) + 1; i < length; i++ {
+			box.positions = append(box.positions, class(i))
+		}
+		if !box.isEmpty() {
+			boxes = append(boxes, box)
+		}
+	}
+
+	common, last := 0, length-1
+	for low[common] == high[common] {
+		common++
+	}
+
+	// Codes that start like the low bound, from the longest shared start to the shortest
+	for i := last; i > common; i-- {
+		from := int(low[i]) + 1
+		if i == last && withLow {
+			from--
+		}
+		add(low[:i], charRange(from, 255))
+	}
+
+	// Codes that part from both bounds where the bounds part from each other
+	from, to := int(low[common])+1, int(high[common])-1
+	if common == last && withLow {
+		from--
+	}
+	if common == last && withHigh {
+		to++
+	}
+	add(low[:common], charRange(from, to))
+
+	// Codes that start like the high bound
+	for i := common + 1; i <= last; i++ {
+		to := int(high[i]) - 1
+		if i == last && withHigh {
+			to++
+		}
+		add(high[:i], charRange(0, to))
+	}
+	return boxes
+}
+
+// The boxes of the codes the range matches: codes of its length that sort between its bounds, and unless matching is
+// strict, the longer codes whose start does, and the shorter codes that sort after the same start of the beginning
+// and no later than the end
+func (cr *codeRange) boxes(strictMatch bool) []codeBox {
+	anyCharacter := func(int) charSet { return codeCharacters }
+	boxes := intervalBoxes(cr.begin, cr.end, true, true, anyCharacter)
+	if strictMatch {
+		return boxes
+	}
+
+	length := len(cr.begin)
+	for shorter := 1; shorter < length; shorter++ {
+		boxes = append(boxes, intervalBoxes(cr.begin[:shorter], cr.end[:shorter], false, true, anyCharacter)...)
+	}
+	for _, box := range intervalBoxes(cr.begin, cr.end, true, false, anyCharacter) {
+		box.minLength, box.maxLength = length+1, codeBoxUnbounded
+		boxes = append(boxes, box)
+	}
+	return boxes
+}
+
+// The box of the codes the pattern matches, with a ? standing for any character of the universe
+func (p codePattern) box(universe charSet) codeBox {
+	base := p.base()
+	box := singleCodeBox(base)
+	for i := 0; i < len(base); i++ {
+		if base[i] == codePatternAnyCharacter {
+			box.positions[i] = universe
+		}
+	}
+	if p.isPrefix() {
+		box.maxLength = codeBoxUnbounded
+	}
+	return box
+}
+
+// A union of boxes that are never empty, and the universe of characters they are used with
+type codeSet struct {
+	universe charSet
+	boxes    []codeBox
+}
+
+func (cc *CodeList) codeSet() codeSet {
+	set := codeSet{universe: codeCharacters, boxes: make([]codeBox, 0, len(cc.codes))}
+	for code := range cc.codes {
+		set.boxes = append(set.boxes, singleCodeBox(code))
+	}
+	for _, cr := range cc.codeRanges {
+		set.boxes = append(set.boxes, cr.boxes(cc.strictMatch)...)
+	}
+	for _, pattern := range cc.patterns {
+		set.boxes = append(set.boxes, pattern.box(codeCharacters))
+	}
+
+	if cc.except != nil {
+		set = set.subtract(cc.except.codeSet())
+	}
+	return set
+}
+
+func (s codeSet) isEmpty() bool {
+	return len(s.boxes) == 0
+}
+
+func (s codeSet) subtract(other codeSet) codeSet {
+	index := newCodeBoxIndex(other.boxes)
+	result := codeSet{universe: s.universe, boxes: make([]codeBox, 0, len(s.boxes))}
+	for _, box := range s.boxes {
+		result.boxes = append(result.boxes, index.remainder(box, s.universe)...)
+	}
+	return result
+}
+
+func (s codeSet) intersect(other codeSet) codeSet {
+	index := newCodeBoxIndex(other.boxes)
+	result := codeSet{universe: s.universe, boxes: make([]codeBox, 0)}
+	for _, box := range s.boxes {
+		for _, i := range index.overlapping(box) {
+			if common := box.intersect(index.boxes[i], s.universe); !common.isEmpty() {
+				result.boxes = append(result.boxes, common)
+			}
+		}
+	}
+	return result
+}
+
+func (s codeSet) equal(other codeSet) bool {
+	return s.subtract(other).isEmpty() && other.subtract(s).isEmpty()
+}
+
+// A list of the codes in the set, written as codes and patterns
+func (s codeSet) toCodeList(strictMatch bool, system CodeSystem) *CodeList {
+	list := &CodeList{codes: make(map[string]bool), codeRanges: make([]codeRange, 0), strictMatch: strictMatch, system: system}
+	for _, box := range s.boxes {
+		lengths := []int{box.minLength}
+		for length := box.minLength + 1; length <= box.maxLength; length++ {
+			lengths = append(lengths, length)
+		}
+
+		for _, length := range lengths {
+			texts := []string{""}
+			for i := 0; i < length; i++ {
+				set := box.at(i, s.universe)
+				chars := set.chars()
+				if s.universe.isSubsetOf(set) {
+					chars = []byte{codePatternAnyCharacter}
+				}
+				longer := make([]string, 0, len(texts)*len(chars))
+				for _, text := range texts {
+					for _, c := range chars {
+						longer = append(longer, text+string(c))
+					}
+				}
+				texts = longer
+			}
+
+			for _, text := range texts {
+				switch {
+				case box.maxLength == codeBoxUnbounded:
+					list.patterns = mergeCodePatterns(list.patterns, []codePattern{{text: text + string(codePatternAnySuffix)}})
+				case strings.IndexByte(text, codePatternAnyCharacter) >= 0:
+					list.patterns = mergeCodePatterns(list.patterns, []codePattern{{text: text}})
+				default:
+					list.codes[text] = true
+				}
+			}
+		}
+	}
+	sort.Slice(list.patterns, func(i, j int) bool { return list.patterns[i].text < list.patterns[j].text })
+	return list
+}
+
+// Boxes sorted by their bounds, to find the ones that may overlap a box without trying them all
+type codeBoxIndex struct {
+	boxes []codeBox
+	lows  []string
+	highs []string
+	reach []string // The greatest high among the boxes up to each one
+}
+
+func newCodeBoxIndex(boxes []codeBox) *codeBoxIndex {
+	index := &codeBoxIndex{boxes: append([]codeBox(nil), boxes...)}
+	index.lows, index.highs = make([]string, len(boxes)), make([]string, len(boxes))
+	for i, box := range index.boxes {
+		index.lows[i], index.highs[i] = box.bounds()
+	}
+	sort.Sort(index)
+
+	index.reach = make([]string, len(boxes))
+	for i, high := range index.highs {
+		index.reach[i] = high
+		if i > 0 && index.reach[i-1] > high {
+			index.reach[i] = index.reach[i-1]
+		}
+	}
+	return index
+}
+
+func (index *codeBoxIndex) Len() int { return len(index.boxes) }
+func (index *codeBoxIndex) Less(i, j int) bool {
+	if index.lows[i] != index.lows[j] {
+		return index.lows[i] < index.lows[j]
+	}
+	return index.boxes[i].minLength < index.boxes[j].minLength
+}
+func (index *codeBoxIndex) Swap(i, j int) {
+	index.boxes[i], index.boxes[j] = index.boxes[j], index.boxes[i]
+	index.lows[i], index.lows[j] = index.lows[j], index.lows[i]
+	index.highs[i], index.highs[j] = index.highs[j], index.highs[i]
+}
+
+// The positions in the index of the boxes whose bounds overlap the box's
+func (index *codeBoxIndex) overlapping(box codeBox) []int {
+	low, high := box.bounds()
+	start := sort.SearchStrings(index.reach, low)
+	end := sort.Search(len(index.lows), func(i int) bool { return index.lows[i] > high })
+
+	result := make([]int, 0)
+	for i := start; i < end; i++ {
+		if index.highs[i] >= low {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// The codes of the box that are in none of the indexed boxes
+func (index *codeBoxIndex) remainder(box codeBox, universe charSet) []codeBox {
+	pieces := []codeBox{box}
+	for _, i := range index.overlapping(box) {
+		remaining := make([]codeBox, 0, len(pieces))
+		for _, piece := range pieces {
+			remaining = append(remaining, piece.subtract(index.boxes[i], universe)...)
+		}
+		if pieces = remaining; len(pieces) == 0 {
+			break
+		}
+	}
+	return pieces
+}
+
+// The entries the lists share, written as they are in the lists where possible: the codes of either list the other
+// includes, the ranges and patterns of either list inside the other, and the overlaps of ranges of the same length.
+// Returns nil if the lists' except lists cannot be merged.
+func (cc *CodeList) commonEntries(other *CodeList) *CodeList {
+	result := &CodeList{codes: make(map[string]bool), codeRanges: make([]codeRange, 0), strictMatch: cc.strictMatch || other.strictMatch, system: cc.system}
+	ccIndex, otherIndex := newCodeBoxIndex(cc.codeSet().boxes), newCodeBoxIndex(other.codeSet().boxes)
+
+	inside := func(boxes []codeBox, index *codeBoxIndex) bool {
+		for _, box := range boxes {
+			if len(index.remainder(box, codeCharacters)) > 0 {
+				return false
+			}
+		}
+		return true
+	}
+	for _, pair := range []struct {
+		list, within *CodeList
+		index        *codeBoxIndex
+	}{{cc, other, otherIndex}, {other, cc, ccIndex}} {
+		for code := range pair.list.codes {
+			if pair.list.Includes(code) && pair.within.Includes(code) {
+				result.codes[code] = true
+			}
+		}
+		for _, cr := range pair.list.codeRanges {
+			if inside(cr.boxes(pair.list.strictMatch), pair.index) {
+				result.codeRanges = append(result.codeRanges, cr)
+			}
+		}
+		for _, pattern := range pair.list.patterns {
+			if inside([]codeBox{pattern.box(codeCharacters)}, pair.index) {
+				result.patterns = mergeCodePatterns(result.patterns, []codePattern{pattern})
+			}
+		}
+	}
+
+	for _, a := range cc.codeRanges {
+		for _, b := range other.codeRanges {
+			common := codeRange{begin: max(a.begin, b.begin), end: min(a.end, b.end)}
+			if len(a.begin) == len(b.begin) && common.begin <= common.end {
+				result.codeRanges = append(result.codeRanges, common)
+			}
+		}
+	}
+	sort.Slice(result.codeRanges, func(i, j int) bool { return result.codeRanges[i].begin < result.codeRanges[j].begin })
+	result.codeRanges = slices.Compact(result.codeRanges)
+
+	switch {
+	case cc.except != nil && other.except != nil:
+		except, err := cc.except.TryMerge(other.except)
+		if err != nil {
+			return nil
+		}
+		result.except = except
+	case cc.except != nil:
+		result.except = cc.except
+	case other.except != nil:
+		result.except = other.except
+	}
+	return result
+}
+
+// Intersect returns the codes in both lists. The result uses strict matching if either list does, and keeps the
+// lists' own ranges, patterns and except lists where they describe it exactly; otherwise it is written as codes and
+// patterns.
+func (cc *CodeList) Intersect(other *CodeList) *CodeList {
+	system := mergedSystem(cc, other)
+	cc, other = cc.forSystem(system), other.forSystem(system)
+
+	common := cc.codeSet().intersect(other.codeSet())
+	if entries := cc.commonEntries(other); entries != nil && entries.codeSet().equal(common) {
+		entries.system = system
+		return entries
+	}
+	return common.toCodeList(cc.strictMatch || other.strictMatch, system)
+}
+
+// IsSubsetOf reports whether every code the list includes the other list includes too
+func (cc *CodeList) IsSubsetOf(other *CodeList) bool {
+	system := mergedSystem(cc, other)
+	return cc.forSystem(system).codeSet().subtract(other.forSystem(system).codeSet()).isEmpty()
+}
+
+// Equal reports whether the lists include the same codes, however they are written
+func (cc *CodeList) Equal(other *CodeList) bool {
+	system := mergedSystem(cc, other)
+	return cc.forSystem(system).codeSet().equal(other.forSystem(system).codeSet())
+}
+
+// Overlaps reports whether any code is in both lists
+func (cc *CodeList) Overlaps(other *CodeList) bool {
+	system := mergedSystem(cc, other)
+	return !cc.forSystem(system).codeSet().intersect(other.forSystem(system).codeSet()).isEmpty()
+}
@@ -0,0 +1,93 @@
+package codes
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("CodeList Algebra", func() {
+
+	Context("Equal", func() {
+		It("Compares ranges with the codes they include", func() {
+			listed := ParseCodeList("99201 99202 99203 99204 99205 99206 99207 99208 99209")
+			Expect(ParseCodeList("99201..99209").WithStrictMatching().Equal(listed)).To(BeTrue())
+			Expect(listed.Equal(ParseCodeList("99201..99209").WithStrictMatching())).To(BeTrue())
+			Expect(ParseCodeList("99201..99210").WithStrictMatching().Equal(listed)).To(BeFalse())
+			Expect(ParseCodeList("99201..99209").Equal(listed)).To(BeFalse())
+		})
+
+		It("Accounts for except lists", func() {
+			Expect(ParseCodeList("A001..A009 EXCEPT [A005]").WithStrictMatching().Equal(ParseCodeList("A001..A004, A006..A009").WithStrictMatching())).To(BeTrue())
+			Expect(ParseCodeList("A001..A010 EXCEPT [A001..A010]").Equal(ParseCodeList("B1 EXCEPT [B1]"))).To(BeTrue())
+		})
+
+		It("Compares overlapping and adjoining ranges", func() {
+			Expect(ParseCodeList("A01..A50, A40..A99").Equal(ParseCodeList("A01..A99"))).To(BeTrue())
+			Expect(ParseCodeList("A09..A10, A01..A08").WithStrictMatching().Equal(ParseCodeList("A01..A10").WithStrictMatching())).To(BeTrue())
+			Expect(ParseCodeList("A09..A10, A01..A08").Equal(ParseCodeList("A01..A10"))).To(BeFalse())
+		})
+
+		It("Compares codes character by character, as Includes does", func() {
+			Expect(ParseCodeList("E11.0..E11.9").Equal(ParseCodeList("E11.0..E11.9, E12.0"))).To(BeFalse())
+			Expect(ParseCodeList("E11.8..E12.1").Equal(ParseCodeList("E11.8, E11.9"))).To(BeFalse())
+			Expect(ParseCodeList("A1..AZ").Equal(ParseCodeList("A1..A9"))).To(BeFalse())
+			Expect(ParseCodeList("A99, B00").WithStrictMatching().Equal(ParseCodeList("A99..B00").WithStrictMatching())).To(BeFalse())
+			Expect(ParseCodeList("B1..A9").Equal(ParseCodeList("Z9 EXCEPT [Z9]"))).To(BeTrue())
+		})
+
+		It("Includes the longer and shorter codes of non-strict ranges", func() {
+			Expect(ParseCodeList("A001..A010").Equal(ParseCodeList("A001..A010").WithStrictMatching())).To(BeFalse())
+			Expect(ParseCodeList("A001..A010").Equal(ParseCodeList("A001..A010, A0011, A01"))).To(BeTrue())
+			Expect(ParseCodeList("A001..A010").WithStrictMatching().Equal(ParseCodeList("A001..A010").WithStrictMatching())).To(BeTrue())
+		})
+	})
+
+	Context("Intersect", func() {
+		It("Intersects ranges and codes", func() {
+			c := ParseCodeList("A001..A010, B001, C001").Intersect(ParseCodeList("A005..A020, C001"))
+			Expect(c.String()).To(Equal("A005..A010,C001"))
+		})
+
+		It("Applies except lists", func() {
+			c := ParseCodeList("A001..A010 EXCEPT [A007]").Intersect(ParseCodeList("A005..A020"))
+			Expect(c.String()).To(Equal("A005..A010 EXCEPT [A007]"))
+			Expect(c.Includes("A007")).To(BeFalse())
+		})
+
+		It("Is empty for disjoint lists", func() {
+			c := ParseCodeList("A001..A010").Intersect(ParseCodeList("B001..B010"))
+			Expect(c.String()).To(Equal(""))
+			Expect(c.Includes("A001")).To(BeFalse())
+		})
+
+		It("Keeps strict matching and the code system", func() {
+			c := ParseCodeListFor(CODE_SYSTEM_ICD10_DIAG, "E10..E14").WithStrictMatching().Intersect(ParseCodeListFor(CODE_SYSTEM_ICD10_DIAG, "E11"))
+			Expect(c.String()).To(Equal("E11"))
+			Expect(c.Includes("E11.9")).To(BeFalse())
+			Expect(c.System()).To(Equal(CODE_SYSTEM_ICD10_DIAG))
+		})
+	})
+
+	Context("IsSubsetOf", func() {
+		It("Checks containment", func() {
+			Expect(ParseCodeList("A003..A005, A009").IsSubsetOf(ParseCodeList("A001..A010"))).To(BeTrue())
+			Expect(ParseCodeList("A003..A011").IsSubsetOf(ParseCodeList("A001..A010"))).To(BeFalse())
+			Expect(ParseCodeList("A003..A011").IsSubsetOf(ParseCodeList("A001..A010, A011"))).To(BeFalse())
+			Expect(ParseCodeList("A003..A011").WithStrictMatching().IsSubsetOf(ParseCodeList("A001..A010, A011").WithStrictMatching())).To(BeTrue())
+		})
+
+		It("Accounts for except lists", func() {
+			Expect(ParseCodeList("A003..A005").IsSubsetOf(ParseCodeList("A001..A010 EXCEPT [A004]"))).To(BeFalse())
+			Expect(ParseCodeList("A003..A005 EXCEPT [A004]").IsSubsetOf(ParseCodeList("A001..A010 EXCEPT [A004]"))).To(BeTrue())
+		})
+	})
+
+	Context("Overlaps", func() {
+		It("Finds common codes", func() {
+			Expect(ParseCodeList("A001..A010").Overlaps(ParseCodeList("A010..A020"))).To(BeTrue())
+			Expect(ParseCodeList("A001..A010").Overlaps(ParseCodeList("A011..A020"))).To(BeFalse())
+			Expect(ParseCodeList("A001..A010 EXCEPT [A010]").Overlaps(ParseCodeList("A010..A020"))).To(BeFalse())
+			Expect(ParseCodeList("A001..A010").Overlaps(ParseCodeList("A0011"))).To(BeTrue())
+			Expect(ParseCodeList("A001..A010").WithStrictMatching().Overlaps(ParseCodeList("A0011"))).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,324 @@
+package codes
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+)
+
+var (
+	ErrMalformedCodeListEncoding = errors.New("Malformed code list encoding")
+)
+
+// Version 2 added patterns and hierarchy matching. Version 1 data is still read.
+const codeListBinaryVersion byte = 2
+
+const (
+	codeListFlagStrict byte = 1 << iota
+	codeListFlagExcept
+	codeListFlagPatterns
+	codeListFlagHierarchy
+)
+
+// The flags each version of the binary encoding may set
+var codeListBinaryFlags = map[byte]byte{
+	1: codeListFlagStrict | codeListFlagExcept,
+	2: codeListFlagStrict | codeListFlagExcept | codeListFlagPatterns | codeListFlagHierarchy,
+}
+
+type codeListJSON struct {
+	System    CodeSystem      `json:"system,omitempty"`
+	Strict    bool            `json:"strict,omitempty"`
+	Hierarchy bool            `json:"hierarchy,omitempty"`
+	Codes     []string        `json:"codes,omitempty"`
+	Ranges    []codeRangeJSON `json:"ranges,omitempty"`
+	Patterns  []string        `json:"patterns,omitempty"`
+	Except    *codeListJSON   `json:"except,omitempty"`
+}
+
+type codeRangeJSON struct {
+	Begin string `json:"begin"`
+	End   string `json:"end"`
+}
+
+// MarshalJSON writes the list as an object with its code system, matching options, codes, ranges, patterns and except
+// list
+func (cc *CodeList) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cc.toJSON())
+}
+
+// UnmarshalJSON reads either the object written by MarshalJSON or a string in the code list grammar
+func (cc *CodeList) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		return cc.UnmarshalText([]byte(text))
+	}
+
+	var encoded codeListJSON
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+
+	list, err := encoded.toCodeList()
+	if err != nil {
+		return err
+	}
+	*cc = *list
+	return nil
+}
+
+func (cc *CodeList) toJSON() *codeListJSON {
+	encoded := &codeListJSON{System: cc.system, Strict: cc.strictMatch, Hierarchy: cc.hierarchyMatch, Codes: cc.sortedCodes()}
+	for _, cr := range cc.codeRanges {
+		encoded.Ranges = append(encoded.Ranges, codeRangeJSON{Begin: cr.begin, End: cr.end})
+	}
+	for _, pattern := range cc.patterns {
+		encoded.Patterns = append(encoded.Patterns, pattern.text)
+	}
+	if cc.except != nil {
+		encoded.Except = cc.except.toJSON()
+	}
+	return encoded
+}
+
+func (encoded *codeListJSON) toCodeList() (*CodeList, error) {
+	list := &CodeList{
+		codes:          make(map[string]bool, len(encoded.Codes)),
+		codeRanges:     make([]codeRange, 0, len(encoded.Ranges)),
+		strictMatch:    encoded.Strict,
+		hierarchyMatch: encoded.Hierarchy,
+		system:         encoded.System,
+	}
+
+	for _, code := range encoded.Codes {
+		if code = NormalizeCode(list.system, code); code == "" {
+			return nil, ErrBlankCode
+		}
+		list.codes[code] = true
+	}
+
+	for _, encodedRange := range encoded.Ranges {
+		cr, err := newCodeRange(NormalizeCode(list.system, encodedRange.Begin), NormalizeCode(list.system, encodedRange.End))
+		if err != nil {
+			return nil, err
+		}
+		list.codeRanges = append(list.codeRanges, cr)
+	}
+
+	for _, text := range encoded.Patterns {
+		pattern, err := newCodePattern(list.system, text)
+		if err != nil {
+			return nil, err
+		}
+		list.patterns = mergeCodePatterns(list.patterns, []codePattern{pattern})
+	}
+
+	if encoded.Except != nil {
+		except, err := encoded.Except.toCodeList()
+		if err != nil {
+			return nil, err
+		}
+		list.except = except.forSystem(list.system)
+	}
+	return list, nil
+}
+
+// MarshalText writes the list in the code list grammar, starting each list with the keywords of its matching options.
+// The code system is not part of the text; UnmarshalText parses with the receiver's code system, as
+// TryParseCodeListStrictFor does.
+func (cc *CodeList) MarshalText() ([]byte, error) {
+	return []byte(cc.text(true)), nil
+}
+
+func (cc *CodeList) UnmarshalText(text []byte) error {
+	list, err := TryParseCodeListStrictFor(cc.system, string(text))
+	if err != nil {
+		return err
+	}
+	*cc = *list
+	return nil
+}
+
+// MarshalBinary writes a compact encoding: a version byte followed by the list, each list being a flags byte, the
+// code system, the codes, the ranges, and then the patterns and the except list when the flags say there are any.
+// Counts and string lengths are unsigned varints.
+func (cc *CodeList) MarshalBinary() ([]byte, error) {
+	return cc.appendBinary([]byte{codeListBinaryVersion}), nil
+}
+
+func (cc *CodeList) appendBinary(data []byte) []byte {
+	var flags byte
+	if cc.strictMatch {
+		flags |= codeListFlagStrict
+	}
+	if cc.except != nil {
+		flags |= codeListFlagExcept
+	}
+	if len(cc.patterns) > 0 {
+		flags |= codeListFlagPatterns
+	}
+	if cc.hierarchyMatch {
+		flags |= codeListFlagHierarchy
+	}
+
+	data = append(data, flags)
+	data = appendBinaryString(data, string(cc.system))
+
+	codes := cc.sortedCodes()
+	data = binary.AppendUvarint(data, uint64(len(codes)))
+	for _, code := range codes {
+		data = appendBinaryString(data, code)
+	}
+
+	data = binary.AppendUvarint(data, uint64(len(cc.codeRanges)))
+	for _, cr := range cc.codeRanges {
+		data = appendBinaryString(data, cr.begin)
+		data = appendBinaryString(data, cr.end)
+	}
+
+	if len(cc.patterns) > 0 {
+		data = binary.AppendUvarint(data, uint64(len(cc.patterns)))
+		for _, pattern := range cc.patterns {
+			data = appendBinaryString(data, pattern.text)
+		}
+	}
+
+	if cc.except != nil {
+		data = cc.except.appendBinary(data)
+	}
+	return data
+}
+
+func (cc *CodeList) UnmarshalBinary(data []byte) error {
+	reader := bytes.NewReader(data)
+	version, err := reader.ReadByte()
+	knownFlags, known := codeListBinaryFlags[version]
+	if err != nil || !known {
+		return ErrMalformedCodeListEncoding
+	}
+
+	list, err := readBinaryCodeList(reader, knownFlags)
+	if err != nil {
+		return err
+	}
+	if reader.Len() > 0 {
+		return ErrMalformedCodeListEncoding
+	}
+	*cc = *list
+	return nil
+}
+
+func readBinaryCodeList(reader *bytes.Reader, knownFlags byte) (*CodeList, error) {
+	flags, err := reader.ReadByte()
+	if err != nil || flags&^knownFlags != 0 {
+		return nil, ErrMalformedCodeListEncoding
+	}
+
+	system, err := readBinaryString(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	codeCount, err := readBinaryCount(reader)
+	if err != nil {
+		return nil, err
+	}
+	list := &CodeList{
+		codes:          make(map[string]bool, codeCount),
+		strictMatch:    flags&codeListFlagStrict != 0,
+		hierarchyMatch: flags&codeListFlagHierarchy != 0,
+		system:         CodeSystem(system),
+	}
+	for i := 0; i < codeCount; i++ {
+		code, err := readBinaryString(reader)
+		if err != nil {
+			return nil, err
+		}
+		list.codes[code] = true
+	}
+
+	rangeCount, err := readBinaryCount(reader)
+	if err != nil {
+		return nil, err
+	}
+	list.codeRanges = make([]codeRange, 0, rangeCount)
+	for i := 0; i < rangeCount; i++ {
+		begin, err := readBinaryString(reader)
+		if err != nil {
+			return nil, err
+		}
+		end, err := readBinaryString(reader)
+		if err != nil {
+			return nil, err
+		}
+		cr, err := newCodeRange(begin, end)
+		if err != nil {
+			return nil, err
+		}
+		list.codeRanges = append(list.codeRanges, cr)
+	}
+
+	if flags&codeListFlagPatterns != 0 {
+		patternCount, err := readBinaryCount(reader)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < patternCount; i++ {
+			text, err := readBinaryString(reader)
+			if err != nil {
+				return nil, err
+			}
+			pattern, err := newCodePattern("", text)
+			if err != nil {
+				return nil, err
+			}
+			list.patterns = append(list.patterns, pattern)
+		}
+	}
+
+	if flags&codeListFlagExcept != 0 {
+		if list.except, err = readBinaryCodeList(reader, knownFlags); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
+
+func appendBinaryString(data []byte, s string) []byte {
+	data = binary.AppendUvarint(data, uint64(len(s)))
+	return append(data, s...)
+}
+
+// Reads a count, which can be no larger than the bytes left to read
+func readBinaryCount(reader *bytes.Reader) (int, error) {
+	count, err := binary.ReadUvarint(reader)
+	if err != nil || count > uint64(reader.Len()) {
+		return 0, ErrMalformedCodeListEncoding
+	}
+	return int(count), nil
+}
+
+func readBinaryString(reader *bytes.Reader) (string, error) {
+	length, err := readBinaryCount(reader)
+	if err != nil {
+		return "", err
+	}
+
+	buffer := make([]byte, length)
+	if _, err := io.ReadFull(reader, buffer); err != nil {
+		return "", ErrMalformedCodeListEncoding
+	}
+	return string(buffer), nil
+}
+
+func (cc *CodeList) sortedCodes() []string {
+	codes := make([]string, 0, len(cc.codes))
+	for code := range cc.codes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
@@ -0,0 +1,146 @@
+package codes
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("CodeList Encoding", func() {
+
+	list := ParseCodeList("A001, A002, A003..A010, B01..B99").
+		WithStrictMatching().
+		Except(ParseCodeList("A005..A006").Except(ParseCodeList("A006")))
+
+	expectEquivalent := func(decoded *CodeList) {
+		Expect(decoded.String()).To(Equal(list.String()))
+		Expect(decoded.strictMatch).To(BeTrue())
+		Expect(decoded.Includes("A005")).To(BeFalse())
+		Expect(decoded.Includes("A006")).To(BeTrue())
+		Expect(decoded.Includes("B010")).To(BeFalse())
+	}
+
+	Context("JSON", func() {
+		It("Round trips", func() {
+			data, err := json.Marshal(list)
+			Expect(err).To(BeNil())
+
+			var decoded CodeList
+			Expect(json.Unmarshal(data, &decoded)).To(Succeed())
+			expectEquivalent(&decoded)
+		})
+
+		It("Writes an object", func() {
+			data, err := json.Marshal(ParseCodeListFor(CODE_SYSTEM_ICD10_DIAG, "E11.9, E10.0..E10.9").Except(ParseCodeList("E10.5")))
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(Equal(`{"system":"ICD10CM","codes":["E119"],"ranges":[{"begin":"E100","end":"E109"}],"except":{"system":"ICD10CM","codes":["E105"]}}`))
+		})
+
+		It("Reads code lists embedded in configuration", func() {
+			var config struct {
+				Diabetes *CodeList `json:"diabetes"`
+				Visits   *CodeList `json:"visits"`
+			}
+			err := json.Unmarshal([]byte(`{
+				"diabetes": {"system": "ICD10CM", "codes": ["E11.9"], "ranges": [{"begin": "E10.0", "end": "E10.9"}]},
+				"visits": "99201..99215 EXCEPT [99211]"
+			}`), &config)
+			Expect(err).To(BeNil())
+			Expect(config.Diabetes.Includes("E11.9")).To(BeTrue())
+			Expect(config.Diabetes.Includes("E10.5")).To(BeTrue())
+			Expect(config.Visits.Includes("99213")).To(BeTrue())
+			Expect(config.Visits.Includes("99211")).To(BeFalse())
+		})
+
+		It("Rejects invalid ranges", func() {
+			var decoded CodeList
+			err := json.Unmarshal([]byte(`{"ranges": [{"begin": "A1", "end": "A100"}]}`), &decoded)
+			Expect(err).To(Equal(ErrInvalidCodeRange))
+		})
+	})
+
+	Context("Text", func() {
+		It("Round trips", func() {
+			text, err := list.MarshalText()
+			Expect(err).To(BeNil())
+
+			var decoded CodeList
+			Expect(decoded.UnmarshalText(text)).To(Succeed())
+			expectEquivalent(&decoded)
+		})
+
+		It("Round trips the matching options String does not write", func() {
+			strict := ParseCodeList("A01..A09").WithStrictMatching()
+			hierarchy := ParseCodeListFor(CODE_SYSTEM_ICD10_DIAG, "E11").WithHierarchyMatching()
+			Expect(ParseCodeList(strict.String()).Equal(strict)).To(BeFalse())
+			Expect(ParseCodeListFor(CODE_SYSTEM_ICD10_DIAG, hierarchy.String()).Includes("E11.9")).To(BeFalse())
+
+			text, err := strict.MarshalText()
+			Expect(err).To(BeNil())
+			var decoded CodeList
+			Expect(decoded.UnmarshalText(text)).To(Succeed())
+			Expect(decoded.Equal(strict)).To(BeTrue())
+
+			text, err = hierarchy.MarshalText()
+			Expect(err).To(BeNil())
+			decoded = CodeList{system: CODE_SYSTEM_ICD10_DIAG}
+			Expect(decoded.UnmarshalText(text)).To(Succeed())
+			Expect(decoded.Includes("E11.9")).To(BeTrue())
+		})
+
+		It("Skips comments", func() {
+			var decoded CodeList
+			Expect(decoded.UnmarshalText([]byte("A01, A02 # diabetes\n// screening\nA03"))).To(Succeed())
+			Expect(decoded.String()).To(Equal("A01,A02,A03"))
+		})
+	})
+
+	Context("Binary", func() {
+		It("Round trips", func() {
+			data, err := list.MarshalBinary()
+			Expect(err).To(BeNil())
+
+			var decoded CodeList
+			Expect(decoded.UnmarshalBinary(data)).To(Succeed())
+			expectEquivalent(&decoded)
+		})
+
+		It("Keeps the code system", func() {
+			data, _ := ParseCodeListFor(CODE_SYSTEM_NDC, "0002-3227-30").MarshalBinary()
+
+			var decoded CodeList
+			Expect(decoded.UnmarshalBinary(data)).To(Succeed())
+			Expect(decoded.System()).To(Equal(CODE_SYSTEM_NDC))
+			Expect(decoded.Includes("0002-3227-30")).To(BeTrue())
+		})
+
+		It("Is smaller than the text form", func() {
+			data, _ := list.MarshalBinary()
+			Expect(len(data)).To(BeNumerically("<", len(list.String())+8))
+		})
+
+		It("Rejects malformed data", func() {
+			data, _ := list.MarshalBinary()
+
+			var decoded CodeList
+			Expect(decoded.UnmarshalBinary(nil)).To(Equal(ErrMalformedCodeListEncoding))
+			Expect(decoded.UnmarshalBinary(data[:len(data)-3])).To(Equal(ErrMalformedCodeListEncoding))
+			Expect(decoded.UnmarshalBinary(append(data, 0))).To(Equal(ErrMalformedCodeListEncoding))
+			Expect(decoded.UnmarshalBinary(append([]byte{99}, data[1:]...))).To(Equal(ErrMalformedCodeListEncoding))
+			Expect(decoded.UnmarshalBinary(append([]byte{data[0], data[1] | 0x80}, data[2:]...))).To(Equal(ErrMalformedCodeListEncoding))
+		})
+
+		It("Reads version 1 data, which has no patterns or hierarchy matching", func() {
+			data, _ := ParseCodeList("A001..A009 EXCEPT [A005]").WithStrictMatching().MarshalBinary()
+			Expect(data[0]).To(Equal(byte(2)))
+
+			var decoded CodeList
+			Expect(decoded.UnmarshalBinary(append([]byte{1}, data[1:]...))).To(Succeed())
+			text, _ := decoded.MarshalText()
+			Expect(string(text)).To(Equal("STRICT A001..A009 EXCEPT [A005]"))
+
+			data, _ = ParseCodeList("E11*").MarshalBinary()
+			Expect(decoded.UnmarshalBinary(append([]byte{1}, data[1:]...))).To(Equal(ErrMalformedCodeListEncoding))
+		})
+	})
+})
@@ -0,0 +1,240 @@
+package codes
+
+import (
+	"errors"
+	"iter"
+	"math"
+)
+
+var (
+	ErrExpansionTooLarge = errors.New("Code list has more codes than the expansion limit")
+)
+
+// The most codes Expand will return. Alphanumeric ranges grow quickly: A0000..Z9999 is 260,000 codes.
+var CodeListExpansionLimit = 100000
+
+// Expansion works on the codes IncrementString walks through from the beginning of a range to its end. Each position
+// of a code in a range keeps the kind of character (digit or letter) it has at the beginning of the range; any other
+// character (such as an ICD dot) stays fixed, and IncrementString does not carry past it. So 99201..99215 is exactly
+// the fifteen codes it expands to. A ? in a pattern stands for a digit or a letter, and a * for any number of them,
+// so a list with a * pattern has no end. The longer codes a non-strict list also matches (V90.1 for V90..V99) are not
+// expanded, nor are the descendants a hierarchy list matches. The except list takes away every code it matches bar
+// such descendants, non-strict or not, so the codes expanded are codes Includes matches unless the list uses
+// hierarchy matching, when its except list matches descendants too. The codes are kept as boxes, as in the set
+// operations (see code_list_algebra.go), so a list is counted without walking it.
+
+// The characters a ? or * stands for when expanding
+var expansionCharacters = newCharSet("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ")
+
+// The characters IncrementString moves a character through: the digits for a digit, the letters for a letter, and
+// any other character alone
+func incrementClass(c byte) charSet {
+	switch {
+	case c >= '0' && c <= '9':
+		return charRange('0', '9')
+	case c >= 'A' && c <= 'Z':
+		return charRange('A', 'Z')
+	default:
+		return newCharSet(string(c))
+	}
+}
+
+// Codes iterates over every code in the list: the individual codes, each code IncrementString walks through in the
+// ranges and the codes the patterns stand for, less the except list. Codes are produced lazily, shortest first for a
+// * pattern, so a caller can stop part way through a huge range or a list without end.
+func (cc *CodeList) Codes() iter.Seq[string] {
+	set := cc.expansionSet()
+	return func(yield func(string) bool) {
+		for _, box := range set.boxes {
+			for code := range box.codes(set.universe) {
+				if !yield(code) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Count returns the number of codes Codes would produce, without walking them. Counts too large for an int, and the
+// counts of lists without end, are returned as math.MaxInt.
+func (cc *CodeList) Count() int {
+	set := cc.expansionSet()
+	total := 0
+	for _, box := range set.boxes {
+		size := box.size(set.universe)
+		if size > math.MaxInt-total {
+			return math.MaxInt
+		}
+		total += size
+	}
+	return total
+}
+
+// Expand returns every code in the list, or ErrExpansionTooLarge if there are more than CodeListExpansionLimit
+func (cc *CodeList) Expand() ([]string, error) {
+	count := cc.Count()
+	if count > CodeListExpansionLimit {
+		return nil, ErrExpansionTooLarge
+	}
+
+	codes := make([]string, 0, count)
+	for code := range cc.Codes() {
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// The codes the list expands to, as boxes that do not overlap, sorted by their lowest codes
+func (cc *CodeList) expansionSet() codeSet {
+	set := codeSet{universe: expansionCharacters, boxes: make([]codeBox, 0, len(cc.codes))}
+	for code := range cc.codes {
+		set.boxes = append(set.boxes, singleCodeBox(code))
+	}
+	for _, cr := range cc.codeRanges {
+		set.boxes = append(set.boxes, cr.expansionBoxes()...)
+	}
+	for _, pattern := range cc.patterns {
+		set.boxes = append(set.boxes, pattern.box(expansionCharacters))
+	}
+
+	if cc.except != nil {
+		longest := 0
+		for _, box := range set.boxes {
+			longest = max(longest, len(box.positions))
+		}
+		set = set.subtract(cc.except.codeSet().inUniverse(set.universe, longest))
+	}
+	return set.disjoint()
+}
+
+// The set with the codes made of another universe's characters, a subset of its own, past the given length. The
+// positions before it are spelled out with the set's own universe, as the codes of boxes with positions of their own
+// there may have characters outside the other universe.
+func (s codeSet) inUniverse(universe charSet, length int) codeSet {
+	result := codeSet{universe: universe, boxes: make([]codeBox, 0, len(s.boxes))}
+	spelledOut := func(box codeBox, count int) []charSet {
+		positions := append(make([]charSet, 0, count), box.positions...)
+		for len(positions) < count {
+			positions = append(positions, s.universe)
+		}
+		return positions
+	}
+
+	for _, box := range s.boxes {
+		if len(box.positions) >= length {
+			result.boxes = append(result.boxes, box)
+			continue
+		}
+		for size := box.minLength; size < length && (box.maxLength == codeBoxUnbounded || size <= box.maxLength); size++ {
+			result.boxes = append(result.boxes, codeBox{positions: spelledOut(box, size), minLength: size, maxLength: size})
+		}
+		if box.maxLength == codeBoxUnbounded || box.maxLength >= length {
+			result.boxes = append(result.boxes, codeBox{positions: spelledOut(box, length), minLength: max(box.minLength, length), maxLength: box.maxLength})
+		}
+	}
+	return result
+}
+
+// The boxes of the codes IncrementString walks through from the beginning of the range to its end
+func (cr *codeRange) expansionBoxes() []codeBox {
+	end := cr.end
+	if last := lastCodeOfRun(cr.begin); end > last {
+		end = last
+	}
+	return intervalBoxes(cr.begin, end, true, true, func(position int) charSet { return incrementClass(cr.begin[position]) })
+}
+
+// The last code IncrementString reaches from the given code before it would have to carry past a character that is
+// neither a digit nor a letter
+func lastCodeOfRun(code string) string {
+	result := []byte(code)
+	for i := len(result) - 1; i >= 0; i-- {
+		class := incrementClass(code[i])
+		if class.size() == 1 {
+			break
+		}
+		result[i] = class.last()
+	}
+	return string(result)
+}
+
+// The set with its boxes split so that no two overlap, sorted by their lowest codes
+func (s codeSet) disjoint() codeSet {
+	index := newCodeBoxIndex(s.boxes)
+	result := codeSet{universe: s.universe, boxes: make([]codeBox, 0, len(s.boxes))}
+	for i, box := range index.boxes {
+		pieces := []codeBox{box}
+		for _, earlier := range index.overlapping(box) {
+			if earlier >= i {
+				continue
+			}
+			remaining := make([]codeBox, 0, len(pieces))
+			for _, piece := range pieces {
+				remaining = append(remaining, piece.subtract(index.boxes[earlier], s.universe)...)
+			}
+			pieces = remaining
+		}
+		result.boxes = append(result.boxes, pieces...)
+	}
+
+	result.boxes = newCodeBoxIndex(result.boxes).boxes
+	return result
+}
+
+// The number of codes in the box, or math.MaxInt if that is too large for an int or the box has no end
+func (b codeBox) size(universe charSet) int {
+	if b.maxLength == codeBoxUnbounded {
+		return math.MaxInt
+	}
+
+	total := 0
+	for length := b.minLength; length <= b.maxLength; length++ {
+		count := 1
+		for i := 0; i < length; i++ {
+			size := b.at(i, universe).size()
+			if count > math.MaxInt/size {
+				return math.MaxInt
+			}
+			count *= size
+		}
+		if count > math.MaxInt-total {
+			return math.MaxInt
+		}
+		total += count
+	}
+	return total
+}
+
+// The codes in the box in order, shortest first
+func (b codeBox) codes(universe charSet) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for length := b.minLength; b.maxLength == codeBoxUnbounded || length <= b.maxLength; length++ {
+			chars := make([][]byte, length)
+			for i := range chars {
+				chars[i] = b.at(i, universe).chars()
+			}
+
+			// Count through the characters of each position like an odometer
+			choices := make([]int, length)
+			code := make([]byte, length)
+			for i := range code {
+				code[i] = chars[i][0]
+			}
+			for {
+				if !yield(string(code)) {
+					return
+				}
+				i := length - 1
+				for ; i >= 0 && choices[i] == len(chars[i])-1; i-- {
+					choices[i] = 0
+					code[i] = chars[i][0]
+				}
+				if i < 0 {
+					break
+				}
+				choices[i]++
+				code[i] = chars[i][choices[i]]
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package codes
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("CodeList Expansion", func() {
+
+	Context("Codes", func() {
+		It("Expands ranges", func() {
+			codes := slices.Collect(ParseCodeList("99201..99215").Codes())
+			Expect(codes).To(HaveLen(15))
+			Expect(codes[0]).To(Equal("99201"))
+			Expect(codes[14]).To(Equal("99215"))
+		})
+
+		It("Includes individual codes and respects the except list", func() {
+			codes := slices.Collect(ParseCodeList("A08..A12, B1 EXCEPT [A10]").Codes())
+			Expect(codes).To(Equal([]string{"A08", "A09", "A11", "A12", "B1"}))
+		})
+
+		It("Stops when the caller does", func() {
+			codes := make([]string, 0)
+			for code := range ParseCodeList("A0000..Z9999").Codes() {
+				codes = append(codes, code)
+				if len(codes) == 3 {
+					break
+				}
+			}
+			Expect(codes).To(Equal([]string{"A0000", "A0001", "A0002"}))
+		})
+
+		It("Walks alphanumeric ranges", func() {
+			codes := slices.Collect(ParseCodeList("A8Y..B0B").Codes())
+			Expect(codes).To(Equal([]string{"A8Y", "A8Z", "A9A", "A9B", "A9C", "A9D", "A9E", "A9F", "A9G", "A9H", "A9I", "A9J",
+				"A9K", "A9L", "A9M", "A9N", "A9O", "A9P", "A9Q", "A9R", "A9S", "A9T", "A9U", "A9V", "A9W", "A9X", "A9Y", "A9Z",
+				"B0A", "B0B"}))
+		})
+	})
+
+	Context("Except lists", func() {
+		It("Takes away every code the except list matches", func() {
+			c := ParseCodeList("E1100..E1199 EXCEPT [E110..E115]")
+			Expect(c.Count()).To(Equal(50))
+			Expect(slices.Collect(c.Codes())[0]).To(Equal("E1150"))
+
+			Expect(slices.Collect(ParseCodeList("?161 EXCEPT [?42,377..B68]").Codes())).NotTo(ContainElement("4161"))
+			Expect(ParseCodeList("E11* EXCEPT [E11.?]").Count()).To(Equal(math.MaxInt))
+			Expect(ParseCodeList("E11.8..E11.9, E12.0 EXCEPT [E11*]").Count()).To(Equal(1))
+		})
+
+		It("Only produces codes Includes matches", func() {
+			random := rand.New(rand.NewSource(7))
+			entry := func() string {
+				code := []byte(fmt.Sprintf("%c%d%d%d", "ABE"[random.Intn(3)], random.Intn(10), random.Intn(10), random.Intn(10)))
+				code = code[:3+random.Intn(2)]
+				switch random.Intn(4) {
+				case 0:
+					return string(code) + ".." + string(code[:len(code)-1]) + "9"
+				case 1:
+					return string(code[:1+random.Intn(3)])
+				case 2:
+					code[random.Intn(len(code))] = '?'
+				}
+				return string(code)
+			}
+			for i := 0; i < 200; i++ {
+				text := fmt.Sprintf("%s, %s EXCEPT [%s, %s, A%d.%d]", entry(), entry(), entry(), entry(), random.Intn(10), random.Intn(10))
+				list := ParseCodeList(text)
+				if random.Intn(2) == 0 {
+					list = list.WithStrictMatching()
+				}
+				for code := range list.Codes() {
+					Expect(list.Includes(code)).To(BeTrue(), text+": "+code)
+				}
+			}
+		})
+	})
+
+	Context("Count", func() {
+		It("Counts without expanding", func() {
+			Expect(ParseCodeList("99201..99215").Count()).To(Equal(15))
+			Expect(ParseCodeList("A0000..Z9999").Count()).To(Equal(260000))
+			Expect(ParseCodeList("A08..A12, B1 EXCEPT [A10]").Count()).To(Equal(5))
+			Expect(ParseCodeList("E11.0..E11.9").Count()).To(Equal(10))
+		})
+
+		It("Agrees with Codes", func() {
+			c := ParseCodeList("A8Y..B0B, C01..C99 EXCEPT [C10..C19, A9C]")
+			Expect(c.Count()).To(Equal(len(slices.Collect(c.Codes()))))
+		})
+
+		It("Saturates for huge ranges", func() {
+			Expect(ParseCodeList("A0000000000000000000..Z9999999999999999999").Count()).To(Equal(math.MaxInt))
+		})
+	})
+
+	Context("Expand", func() {
+		It("Expands small lists", func() {
+			codes, err := ParseCodeList("A1..A3").Expand()
+			Expect(err).To(BeNil())
+			Expect(codes).To(Equal([]string{"A1", "A2", "A3"}))
+		})
+
+		It("Refuses lists over the limit", func() {
+			_, err := ParseCodeList("A0000..Z9999").Expand()
+			Expect(err).To(Equal(ErrExpansionTooLarge))
+		})
+	})
+})
@@ -0,0 +1,202 @@
+package codes
+
+import "sort"
+
+// CodeListIndex answers which of many named code lists contain a code, in one lookup rather than one Includes per
+// list. Codes are looked up in a map and ranges in a partition of the code space into segments, kept in a segment
+// tree so that each range is stored once for each of a few runs of segments rather than once for every segment it
+// covers. A CodeListIndex is immutable and safe for concurrent use.
+type CodeListIndex struct {
+	names      []string
+	matchers   []*CodeMatcher
+	partitions map[CodeSystem]*codeIndexPartition
+}
+
+// The lists of one code system, whose codes all normalize the same way
+type codeIndexPartition struct {
+	codes     map[string][]int
+	intervals map[int]*codeSegments // Keyed like CodeMatcher.intervals, with strict lists by code length
+	patterns  []int                 // The lists with patterns, which are matched one by one
+	hierarchy bool                  // Whether any list uses hierarchy matching
+}
+
+// Boundaries sorted and distinct. Segment 2i is the boundary bounds[i] itself and segment 2i+1 the codes strictly
+// between bounds[i] and bounds[i+1]. The lists are a segment tree over the segments: node 1 covers them all, node n
+// covers half of node n/2's, and segment s is the leaf size+s. A list whose ranges cover a segment is at one of the
+// nodes from that leaf up to the root.
+type codeSegments struct {
+	bounds []string
+	size   int
+	lists  [][]int
+}
+
+// NewCodeListIndex indexes the lists by name. Later changes to the lists do not affect the index.
+func NewCodeListIndex(lists map[string]*CodeList) *CodeListIndex {
+	index := &CodeListIndex{
+		names:      make([]string, 0, len(lists)),
+		matchers:   make([]*CodeMatcher, 0, len(lists)),
+		partitions: make(map[CodeSystem]*codeIndexPartition),
+	}
+	for name := range lists {
+		index.names = append(index.names, name)
+	}
+	sort.Strings(index.names)
+
+	intervals := make(map[CodeSystem]map[int]map[int][]codeInterval)
+	for id, name := range index.names {
+		matcher := lists[name].Compile()
+		index.matchers = append(index.matchers, matcher)
+
+		partition, ok := index.partitions[matcher.system]
+		if !ok {
+			partition = &codeIndexPartition{codes: make(map[string][]int), intervals: make(map[int]*codeSegments)}
+			index.partitions[matcher.system] = partition
+			intervals[matcher.system] = make(map[int]map[int][]codeInterval)
+		}
+		for code := range matcher.codes {
+			partition.codes[code] = append(partition.codes[code], id)
+		}
+		partition.hierarchy = partition.hierarchy || matcher.hierarchyMatch
+		if len(matcher.prefixes) > 0 || len(matcher.patterns) > 0 {
+			partition.patterns = append(partition.patterns, id)
+		}
+		for key, keyIntervals := range matcher.intervals {
+			if intervals[matcher.system][key] == nil {
+				intervals[matcher.system][key] = make(map[int][]codeInterval)
+			}
+			intervals[matcher.system][key][id] = keyIntervals
+		}
+	}
+
+	for system, byKey := range intervals {
+		for key, byList := range byKey {
+			index.partitions[system].intervals[key] = newCodeSegments(byList)
+		}
+	}
+	return index
+}
+
+func newCodeSegments(byList map[int][]codeInterval) *codeSegments {
+	bounds := make([]string, 0)
+	for _, intervals := range byList {
+		for _, interval := range intervals {
+			bounds = append(bounds, interval.begin, interval.end)
+		}
+	}
+	sort.Strings(bounds)
+	bounds = compactSortedStrings(bounds)
+
+	size := 1
+	for size < 2*len(bounds) {
+		size *= 2
+	}
+	segments := &codeSegments{bounds: bounds, size: size, lists: make([][]int, 2*size)}
+	ids := make([]int, 0, len(byList))
+	for id := range byList {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		for _, interval := range byList[id] {
+			// The nodes that together cover the interval's segments and nothing else
+			low := size + 2*sort.SearchStrings(bounds, interval.begin)
+			high := size + 2*sort.SearchStrings(bounds, interval.end) + 1
+			for ; low < high; low, high = low/2, high/2 {
+				if low%2 == 1 {
+					segments.lists[low] = append(segments.lists[low], id)
+					low++
+				}
+				if high%2 == 1 {
+					high--
+					segments.lists[high] = append(segments.lists[high], id)
+				}
+			}
+		}
+	}
+	return segments
+}
+
+func compactSortedStrings(sorted []string) []string {
+	distinct := sorted[:0]
+	for i, s := range sorted {
+		if i == 0 || s != sorted[i-1] {
+			distinct = append(distinct, s)
+		}
+	}
+	return distinct
+}
+
+// The lists whose ranges cover the code, once for each of their ranges that does
+func (segments *codeSegments) find(code string) []int {
+	i := sort.SearchStrings(segments.bounds, code)
+	segment := 2*i - 1
+	switch {
+	case i < len(segments.bounds) && segments.bounds[i] == code:
+		segment = 2 * i
+	case i == 0:
+		return nil
+	}
+
+	found := make([]int, 0)
+	for node := segments.size + segment; node > 0; node /= 2 {
+		found = append(found, segments.lists[node]...)
+	}
+	return found
+}
+
+// Calls found with each list whose codes, ranges or patterns match an already normalized code
+func (partition *codeIndexPartition) find(index *CodeListIndex, code string, found func(id int)) {
+	for _, id := range partition.codes[code] {
+		found(id)
+	}
+	for _, key := range []int{0, len(code)} {
+		if segments, ok := partition.intervals[key]; ok {
+			for _, id := range segments.find(code) {
+				found(id)
+			}
+		}
+	}
+	for _, id := range partition.patterns {
+		if index.matchers[id].matchesPattern(code) {
+			found(id)
+		}
+	}
+}
+
+// Names returns the names of the indexed lists, sorted
+func (index *CodeListIndex) Names() []string {
+	return append([]string(nil), index.names...)
+}
+
+// Lookup returns the sorted names of every list that includes the code
+func (index *CodeListIndex) Lookup(code string) []string {
+	candidates := make(map[int]bool)
+	for system, partition := range index.partitions {
+		normalized := NormalizeCode(system, code)
+		partition.find(index, normalized, func(id int) { candidates[id] = true })
+
+		if partition.hierarchy {
+			for _, ancestor := range icd10cmAncestorCodes(system, normalized) {
+				partition.find(index, ancestor, func(id int) {
+					if index.matchers[id].hierarchyMatch {
+						candidates[id] = true
+					}
+				})
+			}
+		}
+	}
+
+	ids := make([]int, 0, len(candidates))
+	for id := range candidates {
+		if except := index.matchers[id].except; except == nil || !except.Includes(code) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = index.names[id]
+	}
+	return names
+}
@@ -0,0 +1,73 @@
+package codes
+
+import (
+	"fmt"
+	"math/rand"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("CodeListIndex", func() {
+
+	lists := map[string]*CodeList{
+		"Diabetes":     ParseCodeList("E10..E13, E11.0..E11.9 EXCEPT [E11.5]"),
+		"Hypertension": ParseCodeList("I10, I11.0..I13.9"),
+		"Strict":       ParseCodeList("E11..E12, I10").WithStrictMatching(),
+		"Visits":       ParseCodeListFor(CODE_SYSTEM_CPT, "99201..99215, 99381..99397"),
+	}
+
+	It("Returns every list that includes a code", func() {
+		index := NewCodeListIndex(lists)
+		Expect(index.Lookup("E11")).To(Equal([]string{"Diabetes", "Strict"}))
+		Expect(index.Lookup("E11.4")).To(Equal([]string{"Diabetes"}))
+		Expect(index.Lookup("I10")).To(Equal([]string{"Hypertension", "Strict"}))
+		Expect(index.Lookup("99213-25")).To(Equal([]string{"Visits"}))
+		Expect(index.Lookup("Z00")).To(BeEmpty())
+	})
+
+	It("Applies except lists", func() {
+		Expect(NewCodeListIndex(lists).Lookup("E11.5")).To(BeEmpty())
+	})
+
+	It("Stores a range at a few nodes rather than at every segment it covers", func() {
+		many := make(map[string]*CodeList)
+		for i := 0; i < 1000; i++ {
+			many[fmt.Sprintf("List %04d", i)] = ParseCodeList(fmt.Sprintf("A%04d..B%04d", i, i)).WithStrictMatching()
+		}
+		index := NewCodeListIndex(many)
+
+		stored := 0
+		for _, partition := range index.partitions {
+			for _, segments := range partition.intervals {
+				for _, ids := range segments.lists {
+					stored += len(ids)
+				}
+			}
+		}
+		Expect(stored).To(BeNumerically("<", 1000*2*13))
+		Expect(index.Lookup("A0500")).To(HaveLen(501))
+		Expect(index.Lookup("B0500")).To(HaveLen(500))
+	})
+
+	It("Agrees with Includes on every list", func() {
+		random := rand.New(rand.NewSource(12))
+		many := make(map[string]*CodeList)
+		for i := 0; i < 50; i++ {
+			many[fmt.Sprintf("List %02d", i)] = largeCodeList(random)
+		}
+		for name, list := range lists {
+			many[name] = list
+		}
+
+		index := NewCodeListIndex(many)
+		for _, code := range append(randomCodes(random, 2000), "E11", "E11.5", "I12.1", "99213") {
+			expected := make([]string, 0)
+			for _, name := range index.Names() {
+				if many[name].Includes(code) {
+					expected = append(expected, name)
+				}
+			}
+			Expect(index.Lookup(code)).To(Equal(expected), code)
+		}
+	})
+})
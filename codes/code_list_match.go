@@ -0,0 +1,114 @@
+package codes
+
+// An entry of a code list: an individual code, a range or a pattern
+type CodeListEntry struct {
+	Text   string // The entry as String writes it, such as E11, E10..E13 or E11*
+	Source string // The entry as it was written in the parsed text, or "" if the list was not parsed from text
+	Offset int    // The byte offset of Source in the parsed text, or -1 if the list was not parsed from text
+}
+
+// CodeListMatch explains whether and why a code list includes a code
+type CodeListMatch struct {
+	Code     string         // The code, normalized for the list's code system
+	Entry    *CodeListEntry // The entry that matches the code, or nil if none does
+	Ancestor string         // For hierarchy matching, the ancestor of the code that Entry matches
+	Excluded *CodeListMatch // The match in the except list that excludes the code, or nil if it is not excluded
+}
+
+// Included reports whether the list includes the code: an entry matches it and the except list does not exclude it
+func (m CodeListMatch) Included() bool {
+	return m.Entry != nil && m.Excluded == nil
+}
+
+type codeListSource struct {
+	text   string
+	offset int
+}
+
+func (cc *CodeList) addSource(entry string, token codeListToken) {
+	if cc.sources == nil {
+		cc.sources = make(map[string]codeListSource)
+	}
+	if _, present := cc.sources[entry]; !present {
+		cc.sources[entry] = codeListSource{text: token.text, offset: token.offset}
+	}
+}
+
+func (cc *CodeList) copySources() map[string]codeListSource {
+	if len(cc.sources) == 0 {
+		return nil
+	}
+	sources := make(map[string]codeListSource, len(cc.sources))
+	for entry, source := range cc.sources {
+		sources[entry] = source
+	}
+	return sources
+}
+
+// Where an entry is written in both lists, the receiver's source is kept
+func mergedSources(cc, other *CodeList) map[string]codeListSource {
+	sources := cc.copySources()
+	for entry, source := range other.sources {
+		if sources == nil {
+			sources = make(map[string]codeListSource, len(other.sources))
+		}
+		if _, present := sources[entry]; !present {
+			sources[entry] = source
+		}
+	}
+	return sources
+}
+
+// Match explains whether the list includes the code, as Includes reports: which entry matched it, and which entry
+// of the except list excluded it. An excluded code still has the entry it matched. Where several entries match, an
+// individual code is preferred to a range and a range to a pattern.
+func (cc *CodeList) Match(code string) CodeListMatch {
+	return cc.match(code, false)
+}
+
+// Match for a list that matches descendants as well if it or a list it is the except list of uses hierarchy matching
+func (cc *CodeList) match(code string, hierarchy bool) CodeListMatch {
+	hierarchy = hierarchy || cc.hierarchyMatch
+	match := CodeListMatch{Code: NormalizeCode(cc.system, code)}
+	if cc.except != nil {
+		if excluded := cc.except.match(code, hierarchy); excluded.Included() {
+			match.Excluded = &excluded
+		}
+	}
+
+	if match.Entry = cc.matchingEntry(match.Code); match.Entry == nil && hierarchy {
+		for _, ancestor := range icd10cmAncestorCodes(cc.system, match.Code) {
+			if match.Entry = cc.matchingEntry(ancestor); match.Entry != nil {
+				match.Ancestor = ancestor
+				break
+			}
+		}
+	}
+	return match
+}
+
+func (cc *CodeList) matchingEntry(code string) *CodeListEntry {
+	if cc.codes[code] {
+		return cc.entry(code)
+	}
+	for _, rng := range cc.codeRanges {
+		if rng.contains(code, cc.strictMatch) {
+			return cc.entry(rng.begin + ".." + rng.end)
+		}
+	}
+	for _, pattern := range cc.patterns {
+		if pattern.matches(code) {
+			return cc.entry(pattern.text)
+		}
+	}
+	return nil
+}
+
+func (cc *CodeList) entry(text string) *CodeListEntry {
+	entry := &CodeListEntry{Text: text, Offset: -1}
+	if source, ok := cc.sources[text]; ok {
+		entry.Source = source.text
+		entry.Offset = source.offset
+	}
+	return entry
+}
@@ -0,0 +1,82 @@
+package codes
+
+import (
+	"math/rand"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("CodeList Match", func() {
+
+	It("Cites the individual code that matched", func() {
+		match := ParseCodeList("I10, e11.9").Match("E11.9")
+		Expect(match.Included()).To(BeTrue())
+		Expect(match.Code).To(Equal("E11.9"))
+		Expect(*match.Entry).To(Equal(CodeListEntry{Text: "E11.9", Source: "e11.9", Offset: 5}))
+		Expect(match.Excluded).To(BeNil())
+	})
+
+	It("Cites the range or pattern that matched", func() {
+		list := ParseCodeList("A001, B01..B09, C1*")
+		Expect(*list.Match("B05").Entry).To(Equal(CodeListEntry{Text: "B01..B09", Source: "B01..B09", Offset: 6}))
+		Expect(*list.Match("C123").Entry).To(Equal(CodeListEntry{Text: "C1*", Source: "C1*", Offset: 16}))
+	})
+
+	It("Prefers individual codes to ranges", func() {
+		Expect(ParseCodeList("A01..A09, A05").Match("A05").Entry.Text).To(Equal("A05"))
+	})
+
+	It("Reports codes no entry matches", func() {
+		match := ParseCodeList("A01..A09").Match("B01")
+		Expect(match.Included()).To(BeFalse())
+		Expect(match.Entry).To(BeNil())
+		Expect(match.Excluded).To(BeNil())
+	})
+
+	It("Reports the except entry that excluded a code", func() {
+		match := ParseCodeList("E10..E13 EXCEPT [E11, E12]").Match("E12")
+		Expect(match.Included()).To(BeFalse())
+		Expect(match.Entry.Text).To(Equal("E10..E13"))
+		Expect(match.Excluded).NotTo(BeNil())
+		Expect(*match.Excluded.Entry).To(Equal(CodeListEntry{Text: "E12", Source: "E12", Offset: 22}))
+	})
+
+	It("Follows nested except lists", func() {
+		list := ParseCodeList("A001..A010 EXCEPT [A003..A006 EXCEPT [A004]]")
+		Expect(list.Match("A004").Included()).To(BeTrue())
+		Expect(list.Match("A005").Excluded.Entry.Text).To(Equal("A003..A006"))
+	})
+
+	It("Cites the source text with the list's code system", func() {
+		match := ParseCodeListFor(CODE_SYSTEM_ICD10_DIAG, "E11.65").Match("e1165")
+		Expect(match.Code).To(Equal("E1165"))
+		Expect(*match.Entry).To(Equal(CodeListEntry{Text: "E1165", Source: "E11.65", Offset: 0}))
+	})
+
+	It("Reports the ancestor a hierarchy match went through", func() {
+		match := ParseCodeList("E11").WithHierarchyMatching().Match("E11.65")
+		Expect(match.Included()).To(BeTrue())
+		Expect(match.Ancestor).To(Equal("E11"))
+		Expect(match.Entry.Source).To(Equal("E11"))
+	})
+
+	It("Keeps sources through Merge and Except", func() {
+		list := ParseCodeList("A01").Merge(ParseCodeList("  B01")).Except(ParseCodeList("A01"))
+		Expect(list.Match("B01").Entry.Offset).To(Equal(2))
+		Expect(list.Match("A01").Excluded.Entry.Source).To(Equal("A01"))
+	})
+
+	It("Has no source for lists that were not parsed", func() {
+		list := &CodeList{}
+		Expect(list.UnmarshalJSON([]byte(`{"codes":["A01"]}`))).To(Succeed())
+		Expect(*list.Match("A01").Entry).To(Equal(CodeListEntry{Text: "A01", Offset: -1}))
+	})
+
+	It("Agrees with Includes", func() {
+		random := rand.New(rand.NewSource(21))
+		list := largeCodeList(random)
+		for _, code := range randomCodes(random, 2000) {
+			Expect(list.Match(code).Included()).To(Equal(list.Includes(code)), code)
+		}
+	})
+})
@@ -0,0 +1,417 @@
+package codes
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	kerrors "github.com/koanhealth/gotools/errors"
+)
+
+var (
+	ErrUnexpectedCharacter = errors.New("Unexpected character")
+	ErrUnexpectedToken     = errors.New("Unexpected token")
+	ErrUnclosedGroup       = errors.New("Group is missing its closing bracket")
+)
+
+// ParseError locates a problem in a code list. Reason is one of the code list sentinel errors (ErrMalformedCodeList,
+// ErrInvalidCodeRange, ErrUnexpectedCharacter, ...), and Offset is the byte offset of Token in the input. Problems at
+// the end of the input have an empty Token.
+type ParseError struct {
+	Token  string
+	Offset int
+	Reason error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at offset %d ('%s')", e.Reason.Error(), e.Offset, e.Token)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Reason
+}
+
+// How the parser treats problems in a code list
+type codeListParseMode int
+
+const (
+	// Characters that cannot be part of a code are separators, and other problems are returned as sentinel errors
+	codeListParseCompatible codeListParseMode = iota
+	// The first problem is returned as a *ParseError
+	codeListParseStrict
+	// Every problem is collected as a *ParseError, skipping what cannot be parsed
+	codeListParseLenient
+)
+
+// Code lists are written as codes and ranges separated by commas or whitespace:
+//
+//	STRICT A01, B02..B09, [C10 C11] EXCEPT [B05]  # comments run to the end of the line
+//
+// Codes may also be patterns such as E11* or Z79.4? (see code_pattern.go). STRICT turns on strict matching and
+// HIERARCHY hierarchy matching (see WithHierarchyMatching), square brackets or parentheses group part of a list, and
+// EXCEPT removes the codes of the group (or the codes up to the next EXCEPT) that follows it. Keywords apply to the
+// list they start, not to its except list. Repeated EXCEPT clauses all apply to the list, so A EXCEPT B EXCEPT C and
+// A EXCEPT [B] EXCEPT [C] exclude both B and C; an except list only has its own EXCEPT inside its brackets. An EXCEPT
+// inside a group applies to that group, so a group with an EXCEPT must make up its whole list.
+//
+// The compatible parser (ParseCodeList and TryParseCodeList) reads only what CodeList.String() writes: codes, ranges,
+// patterns, EXCEPT and square brackets. Other characters separate codes, as they always have, so STRICT and HIERARCHY
+// are codes there and comments are not skipped. The strict and lenient parsers and UnmarshalText read the whole
+// grammar. To store a list as text and read back an equivalent list, matching options and all, use MarshalText and
+// UnmarshalText; ParseCodeList(cl.String()) rebuilds the entries but not strict or hierarchy matching.
+
+const (
+	codeListKeywordExcept    = "EXCEPT"
+	codeListKeywordStrict    = "STRICT"
+	codeListKeywordHierarchy = "HIERARCHY"
+)
+
+type codeListTokenKind int
+
+const (
+	codeListTokenCode codeListTokenKind = iota
+	codeListTokenExcept
+	codeListTokenStrict
+	codeListTokenHierarchy
+	codeListTokenOpenBracket
+	codeListTokenCloseBracket
+	codeListTokenOpenParen
+	codeListTokenCloseParen
+	codeListTokenInvalid
+	codeListTokenEnd
+)
+
+var codeListClosingTokens = map[codeListTokenKind]codeListTokenKind{
+	codeListTokenOpenBracket: codeListTokenCloseBracket,
+	codeListTokenOpenParen:   codeListTokenCloseParen,
+}
+
+type codeListToken struct {
+	kind   codeListTokenKind
+	text   string
+	offset int
+}
+
+// Splits a code list into tokens. Hyphens are code characters when the list belongs to a code system. Commas and
+// whitespace separate codes; other characters that cannot be part of a code are separators too in compatible mode,
+// as are the characters of comments and parentheses, and invalid tokens otherwise. The matching keywords are codes in
+// compatible mode. In lenient mode the code characters next to an invalid one are dropped with it, so that "A0/02" is
+// skipped rather than read as A0 and 02.
+func lexCodeList(input string, system CodeSystem, mode codeListParseMode) []codeListToken {
+	isCodeCharacter := func(c byte) bool {
+		return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '.' ||
+			(c == '-' && system != "") || strings.IndexByte("*%?_", c) >= 0
+	}
+
+	tokens := make([]codeListToken, 0)
+	for offset := 0; offset < len(input); {
+		c := input[offset]
+		switch {
+		case mode != codeListParseCompatible && (c == '#' || strings.HasPrefix(input[offset:], "//")):
+			for offset < len(input) && input[offset] != '\n' {
+				offset++
+			}
+		case c == '[':
+			tokens = append(tokens, codeListToken{kind: codeListTokenOpenBracket, text: "[", offset: offset})
+			offset++
+		case c == ']':
+			tokens = append(tokens, codeListToken{kind: codeListTokenCloseBracket, text: "]", offset: offset})
+			offset++
+		case c == '(' && mode != codeListParseCompatible:
+			tokens = append(tokens, codeListToken{kind: codeListTokenOpenParen, text: "(", offset: offset})
+			offset++
+		case c == ')' && mode != codeListParseCompatible:
+			tokens = append(tokens, codeListToken{kind: codeListTokenCloseParen, text: ")", offset: offset})
+			offset++
+		case isCodeCharacter(c):
+			start := offset
+			for offset < len(input) && isCodeCharacter(input[offset]) {
+				offset++
+			}
+			text := input[start:offset]
+			kind := codeListTokenCode
+			switch keyword := strings.ToUpper(text); {
+			case keyword == codeListKeywordExcept:
+				kind = codeListTokenExcept
+			case keyword == codeListKeywordStrict && mode != codeListParseCompatible:
+				kind = codeListTokenStrict
+			case keyword == codeListKeywordHierarchy && mode != codeListParseCompatible:
+				kind = codeListTokenHierarchy
+			}
+			tokens = append(tokens, codeListToken{kind: kind, text: text, offset: start})
+		case c == ',' || c == ' ' || c == '\t' || c == '\n' || c == '\r' || mode == codeListParseCompatible:
+			offset++
+		default:
+			start := offset
+			offset++
+			for offset < len(input) && !utf8.RuneStart(input[offset]) {
+				offset++
+			}
+			tokens = append(tokens, codeListToken{kind: codeListTokenInvalid, text: input[start:offset], offset: start})
+		}
+	}
+	if mode == codeListParseLenient {
+		tokens = dropInvalidWords(tokens)
+	}
+	return append(tokens, codeListToken{kind: codeListTokenEnd, offset: len(input)})
+}
+
+// Removes the codes and keywords that touch an invalid token, keeping the invalid tokens to be reported
+func dropInvalidWords(tokens []codeListToken) []codeListToken {
+	inWord := func(token codeListToken) bool {
+		switch token.kind {
+		case codeListTokenCode, codeListTokenExcept, codeListTokenStrict, codeListTokenHierarchy, codeListTokenInvalid:
+			return true
+		}
+		return false
+	}
+	touching := func(first, second codeListToken) bool {
+		return inWord(first) && inWord(second) && first.offset+len(first.text) == second.offset
+	}
+
+	dropped := make([]bool, len(tokens))
+	for index, token := range tokens {
+		if token.kind != codeListTokenInvalid {
+			continue
+		}
+		for before := index - 1; before >= 0 && touching(tokens[before], tokens[before+1]); before-- {
+			dropped[before] = tokens[before].kind != codeListTokenInvalid
+		}
+		for after := index + 1; after < len(tokens) && touching(tokens[after-1], tokens[after]); after++ {
+			dropped[after] = tokens[after].kind != codeListTokenInvalid
+		}
+	}
+
+	kept := tokens[:0]
+	for index, token := range tokens {
+		if !dropped[index] {
+			kept = append(kept, token)
+		}
+	}
+	return kept
+}
+
+type codeListParser struct {
+	tokens   []codeListToken
+	position int
+	system   CodeSystem
+	mode     codeListParseMode
+	errors   kerrors.ErrorSlice
+}
+
+func parseCodeList(input string, system CodeSystem, mode codeListParseMode) (*CodeList, kerrors.ErrorSlice) {
+	parser := &codeListParser{tokens: lexCodeList(input, system, mode), system: system, mode: mode}
+	if strings.TrimSpace(input) == "" {
+		parser.fail(codeListToken{kind: codeListTokenEnd}, ErrBlankCode)
+		return nil, parser.errors
+	}
+
+	list, err := parser.parseList(codeListTokenEnd, true, true)
+	if err != nil {
+		return nil, parser.errors
+	}
+	return list, parser.errors
+}
+
+// Records a problem with a token. A non-nil result means parsing stops; in lenient mode it continues after the token.
+func (p *codeListParser) fail(token codeListToken, reason error) error {
+	var err error = &ParseError{Token: token.text, Offset: token.offset, Reason: reason}
+	if p.mode == codeListParseCompatible {
+		err = reason
+		if reason == ErrUnexpectedToken || reason == ErrUnclosedGroup {
+			err = ErrMalformedCodeList
+		}
+	}
+
+	p.errors = append(p.errors, err)
+	if p.mode == codeListParseLenient {
+		return nil
+	}
+	return err
+}
+
+func (p *codeListParser) peek() codeListToken {
+	return p.tokens[p.position]
+}
+
+func (p *codeListParser) next() codeListToken {
+	token := p.tokens[p.position]
+	if token.kind != codeListTokenEnd {
+		p.position++
+	}
+	return token
+}
+
+// Parses a list up to (but not including) the closing token. Only the top level list may be empty. A list without
+// EXCEPT clauses of its own, such as an unbracketed except list, stops at the next EXCEPT.
+func (p *codeListParser) parseList(closing codeListTokenKind, topLevel, takesExcept bool) (*CodeList, error) {
+	list := &CodeList{codes: make(map[string]bool), codeRanges: make([]codeRange, 0, 5), system: p.system}
+	start := p.peek()
+	for keyword := start.kind; keyword == codeListTokenStrict || keyword == codeListTokenHierarchy; keyword = p.peek().kind {
+		p.next()
+		list.strictMatch = list.strictMatch || keyword == codeListTokenStrict
+		list.hierarchyMatch = list.hierarchyMatch || keyword == codeListTokenHierarchy
+	}
+
+	items := 0
+	groups := make([]*CodeList, 0)
+	for kind := p.peek().kind; kind != closing && kind != codeListTokenExcept; kind = p.peek().kind {
+		token := p.next()
+		switch token.kind {
+		case codeListTokenCode:
+			if err := p.addCode(list, token); err != nil {
+				return nil, err
+			}
+		case codeListTokenOpenBracket, codeListTokenOpenParen:
+			group, err := p.parseGroup(token)
+			if err != nil {
+				return nil, err
+			}
+			if group == nil {
+				continue
+			}
+			if group.except != nil {
+				// Only a list made up of a single group can keep the group's EXCEPT
+				if p.peek().kind != closing && p.peek().kind != codeListTokenExcept || items > 0 {
+					if err := p.fail(token, ErrMalformedCodeList); err != nil {
+						return nil, err
+					}
+					continue
+				}
+			}
+			groups = append(groups, group)
+		case codeListTokenEnd:
+			if err := p.fail(token, ErrUnclosedGroup); err != nil {
+				return nil, err
+			}
+			return p.finishList(list, items, groups, nil, closing, topLevel, start)
+		case codeListTokenInvalid:
+			if err := p.fail(token, ErrUnexpectedCharacter); err != nil {
+				return nil, err
+			}
+			continue
+		default:
+			if err := p.fail(token, ErrUnexpectedToken); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		items++
+	}
+	if !takesExcept {
+		return p.finishList(list, items, groups, nil, closing, topLevel, start)
+	}
+
+	excepts := make([]*CodeList, 0)
+	for p.peek().kind == codeListTokenExcept {
+		p.next()
+
+		var except *CodeList
+		var err error
+		if token := p.peek(); token.kind == codeListTokenOpenBracket || token.kind == codeListTokenOpenParen {
+			except, err = p.parseGroup(p.next())
+		} else {
+			except, err = p.parseList(closing, false, false)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if except != nil {
+			excepts = append(excepts, except)
+		}
+	}
+
+	for token := p.peek(); token.kind != closing; token = p.peek() {
+		reason := ErrUnexpectedToken
+		if token.kind == codeListTokenEnd {
+			reason = ErrUnclosedGroup
+		}
+		if err := p.fail(token, reason); err != nil {
+			return nil, err
+		}
+		if token.kind == codeListTokenEnd {
+			break
+		}
+		p.next()
+	}
+
+	return p.finishList(list, items, groups, excepts, closing, topLevel, start)
+}
+
+func (p *codeListParser) finishList(list *CodeList, items int, groups, excepts []*CodeList, closing codeListTokenKind, topLevel bool, start codeListToken) (*CodeList, error) {
+	if items == 0 && len(excepts) == 0 && !topLevel {
+		if err := p.fail(start, ErrBlankCode); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	result := list
+	if items == 1 && len(groups) == 1 {
+		// A list that is just a group, such as [A..B EXCEPT [C]]
+		result = groups[0]
+		result.strictMatch = result.strictMatch || list.strictMatch
+		result.hierarchyMatch = result.hierarchyMatch || list.hierarchyMatch
+	} else {
+		for _, group := range groups {
+			result = result.Merge(group)
+		}
+	}
+
+	for _, except := range excepts {
+		result = result.Except(except)
+	}
+	return result, nil
+}
+
+// Parses a group after its opening token. The result is nil, without an error, for an empty group in lenient mode.
+func (p *codeListParser) parseGroup(open codeListToken) (*CodeList, error) {
+	closing := codeListClosingTokens[open.kind]
+	group, err := p.parseList(closing, false, true)
+	if err != nil {
+		return nil, err
+	}
+	p.next()
+	return group, nil
+}
+
+func (p *codeListParser) addCode(list *CodeList, token codeListToken) error {
+	if isCodePattern(token.text) {
+		if strings.Contains(token.text, "..") {
+			return p.fail(token, ErrInvalidCodePattern)
+		}
+		pattern, err := newCodePattern(p.system, token.text)
+		if err != nil {
+			return p.fail(token, err)
+		}
+		list.patterns = mergeCodePatterns(list.patterns, []codePattern{pattern})
+		list.addSource(pattern.text, token)
+		return nil
+	}
+
+	rangeBounds := strings.Split(strings.ToUpper(token.text), "..")
+	switch len(rangeBounds) {
+	case 1:
+		if code := NormalizeCode(p.system, rangeBounds[0]); len(code) > 0 {
+			list.codes[code] = true
+			list.addSource(code, token)
+		}
+	case 2:
+		begin := NormalizeCode(p.system, rangeBounds[0])
+		end := NormalizeCode(p.system, rangeBounds[1])
+		if len(begin) == 0 || len(end) == 0 {
+			return p.fail(token, ErrMalformedCodeList)
+		}
+
+		newRange, err := newCodeRange(begin, end)
+		if err != nil {
+			return p.fail(token, err)
+		}
+		list.codeRanges = append(list.codeRanges, newRange)
+		list.addSource(begin+".."+end, token)
+	default:
+		return p.fail(token, ErrMalformedCodeList)
+	}
+	return nil
+}
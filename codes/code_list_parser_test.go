@@ -0,0 +1,262 @@
+package codes
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("CodeList Grammar", func() {
+
+	roundTrip := func(list *CodeList) *CodeList {
+		parsed, err := TryParseCodeList(list.String())
+		Expect(err).To(BeNil(), list.String())
+		Expect(parsed.String()).To(Equal(list.String()))
+		return parsed
+	}
+
+	Context("EXCEPT", func() {
+		It("Parses an EXCEPT clause", func() {
+			c := ParseCodeList("A001..A010 EXCEPT [A005]")
+			Expect(c.Includes("A004")).To(BeTrue())
+			Expect(c.Includes("A005")).To(BeFalse())
+			Expect(c.Includes("EXCEPT")).To(BeFalse())
+		})
+
+		It("Parses an EXCEPT clause without brackets", func() {
+			c := ParseCodeList("A001..A010 except A005, A006")
+			Expect(c.String()).To(Equal("A001..A010 EXCEPT [A005,A006]"))
+		})
+
+		It("Parses nested EXCEPT clauses", func() {
+			c := ParseCodeList("A001..A010 EXCEPT [A003..A006 EXCEPT [A004]]")
+			Expect(c.Includes("A003")).To(BeFalse())
+			Expect(c.Includes("A004")).To(BeTrue())
+			Expect(c.Includes("A007")).To(BeTrue())
+		})
+
+		It("Combines repeated EXCEPT clauses, with or without brackets", func() {
+			for _, input := range []string{"A001..A010 EXCEPT [A003] EXCEPT [A004]", "A001..A010 EXCEPT A003 EXCEPT A004", "A001..A010 EXCEPT A003 EXCEPT [A004]"} {
+				c := ParseCodeList(input)
+				Expect(c.Includes("A003")).To(BeFalse(), input)
+				Expect(c.Includes("A004")).To(BeFalse(), input)
+				Expect(c.Includes("A005")).To(BeTrue(), input)
+				Expect(c.String()).To(Equal("A001..A010 EXCEPT [A003,A004]"), input)
+			}
+		})
+
+		It("Rejects codes after an EXCEPT clause", func() {
+			_, err := TryParseCodeList("A001..A010 EXCEPT [A005] A011")
+			Expect(err).To(Equal(ErrMalformedCodeList))
+		})
+
+		It("Rejects empty EXCEPT clauses", func() {
+			_, err := TryParseCodeList("A001..A010 EXCEPT []")
+			Expect(err).To(Equal(ErrBlankCode))
+		})
+	})
+
+	Context("Groups", func() {
+		It("Merges groups into the list", func() {
+			c := ParseCodeList("A001, [B001..B005 [C001]], D001")
+			Expect(c.String()).To(Equal("A001,B001..B005,C001,D001"))
+
+			c, err := TryParseCodeListStrict("A001, [B001..B005 (C001)], D001")
+			Expect(err).To(BeNil())
+			Expect(c.String()).To(Equal("A001,B001..B005,C001,D001"))
+		})
+
+		It("Only groups with square brackets in the compatible parser", func() {
+			Expect(ParseCodeList("A001 (A002)").String()).To(Equal("A001,A002"))
+		})
+
+		It("Accepts a list that is a single group", func() {
+			c := ParseCodeList("[A001..A010 EXCEPT [A005]]")
+			Expect(c.String()).To(Equal("A001..A010 EXCEPT [A005]"))
+		})
+
+		It("Rejects a group with an EXCEPT among other codes", func() {
+			_, err := TryParseCodeList("B001, [A001..A010 EXCEPT [A005]]")
+			Expect(err).To(Equal(ErrMalformedCodeList))
+		})
+
+		It("Rejects unbalanced brackets", func() {
+			_, err := TryParseCodeList("[A001, A002")
+			Expect(err).To(Equal(ErrMalformedCodeList))
+
+			_, err = TryParseCodeList("A001, A002]")
+			Expect(err).To(Equal(ErrMalformedCodeList))
+
+			_, err = TryParseCodeList("[A001, A002)")
+			Expect(err).To(Equal(ErrMalformedCodeList))
+		})
+	})
+
+	Context("Comments", func() {
+		It("Ignores comments", func() {
+			c, err := TryParseCodeListStrict(`
+# Diabetes
+E10..E13 // all types
+EXCEPT [E12] # malnutrition related`)
+			Expect(err).To(BeNil())
+			Expect(c.String()).To(Equal("E10..E13 EXCEPT [E12]"))
+		})
+
+		It("Reads comment characters as separators in the compatible parser", func() {
+			Expect(ParseCodeList("E10 # E11").String()).To(Equal("E10,E11"))
+		})
+	})
+
+	Context("STRICT", func() {
+		It("Turns on strict matching", func() {
+			c, err := TryParseCodeListStrict("STRICT code12..code20")
+			Expect(err).To(BeNil())
+			Expect(c.Includes("code125")).To(BeFalse())
+			Expect(c.String()).To(Equal("CODE12..CODE20"))
+
+			text, _ := c.MarshalText()
+			Expect(string(text)).To(Equal("STRICT CODE12..CODE20"))
+		})
+
+		It("Is a code in the compatible parser", func() {
+			c := ParseCodeList("STRICT code12..code20")
+			Expect(c.Includes("STRICT")).To(BeTrue())
+			Expect(c.Includes("code125")).To(BeTrue())
+		})
+	})
+
+	Context("Round trips", func() {
+		It("Round trips simple lists", func() {
+			roundTrip(ParseCodeList("A001, A002,A003..A010,  A100  "))
+			roundTrip(ParseCodeList("A1"))
+		})
+
+		It("Round trips except chains", func() {
+			c := ParseCodeList("A001..A010, A100").Except(ParseCodeList("A005..A006")).Except(ParseCodeList("A007"))
+			parsed := roundTrip(c)
+			Expect(parsed.Includes("A006")).To(BeFalse())
+			Expect(parsed.Includes("A007")).To(BeFalse())
+			Expect(parsed.Includes("A008")).To(BeTrue())
+
+			nested := ParseCodeList("A..Z").Except(ParseCodeList("B..D").Except(ParseCodeList("C")))
+			parsed = roundTrip(nested)
+			Expect(parsed.Includes("C")).To(BeTrue())
+			Expect(parsed.Includes("D")).To(BeFalse())
+		})
+
+		It("Round trips strict lists through MarshalText", func() {
+			c := ParseCodeList("code12..code20").WithStrictMatching().Except(ParseCodeList("code15"))
+			text, err := c.MarshalText()
+			Expect(err).To(BeNil())
+
+			parsed := &CodeList{}
+			Expect(parsed.UnmarshalText(text)).To(Succeed())
+			Expect(parsed.Includes("code125")).To(BeFalse())
+			Expect(parsed.Includes("code15")).To(BeFalse())
+			Expect(parsed.String()).To(Equal(c.String()))
+		})
+
+		It("Round trips code system lists", func() {
+			c := ParseCodeListFor(CODE_SYSTEM_ICD10_DIAG, "E11.0..E11.9 EXCEPT [E11.5]")
+			parsed := ParseCodeListFor(CODE_SYSTEM_ICD10_DIAG, c.String())
+			Expect(parsed.String()).To(Equal(c.String()))
+		})
+	})
+
+	Context("Strict parsing", func() {
+		parseError := func(input string) *ParseError {
+			_, err := TryParseCodeListStrict(input)
+			var parseErr *ParseError
+			Expect(errors.As(err, &parseErr)).To(BeTrue(), input)
+			return parseErr
+		}
+
+		It("Accepts well formed lists", func() {
+			c, err := TryParseCodeListStrict("A001, A002..A009 EXCEPT [A005] # comment")
+			Expect(err).To(BeNil())
+			Expect(c.String()).To(Equal("A001,A002..A009 EXCEPT [A005]"))
+		})
+
+		It("Rejects characters the compatible parser skips", func() {
+			for input, token := range map[string]string{
+				"A001, A0/02":  "/",
+				"A001!2":       "!",
+				"E11.9-E11.65": "-",
+			} {
+				err := parseError(input)
+				Expect(err.Reason).To(Equal(ErrUnexpectedCharacter))
+				Expect(err.Token).To(Equal(token))
+			}
+
+			Expect(ParseCodeList("A001, A0/02").String()).To(Equal("02,A0,A001"))
+		})
+
+		It("Reports byte offsets", func() {
+			err := parseError("A001, A0/02")
+			Expect(err.Offset).To(Equal(8))
+			Expect(err.Error()).To(ContainSubstring("offset 8"))
+
+			err = parseError("A001 B01..B002")
+			Expect(err.Reason).To(Equal(ErrInvalidCodeRange))
+			Expect(err.Token).To(Equal("B01..B002"))
+			Expect(err.Offset).To(Equal(5))
+			Expect(errors.Is(err, ErrInvalidCodeRange)).To(BeTrue())
+		})
+
+		It("Reports structural problems", func() {
+			Expect(parseError("V90..V98..V99").Reason).To(Equal(ErrMalformedCodeList))
+			Expect(parseError("A001 ]").Reason).To(Equal(ErrUnexpectedToken))
+			Expect(parseError("A001 EXCEPT [A002] A003").Token).To(Equal("A003"))
+
+			err := parseError("[A001")
+			Expect(err.Reason).To(Equal(ErrUnclosedGroup))
+			Expect(err.Offset).To(Equal(5))
+
+			err = parseError("  ")
+			Expect(err.Reason).To(Equal(ErrBlankCode))
+		})
+
+		It("Keeps hyphens in code system lists", func() {
+			c, err := TryParseCodeListStrictFor(CODE_SYSTEM_LOINC, "4548-4, 17856-6")
+			Expect(err).To(BeNil())
+			Expect(c.Includes("4548-4")).To(BeTrue())
+		})
+	})
+
+	Context("Lenient parsing", func() {
+		It("Collects every problem", func() {
+			c, errs := ParseCodeListLenient("A001, A0/02, B01..B002, C001! [D001")
+			Expect(errs).To(HaveLen(4))
+
+			reasons := make([]error, len(errs))
+			for index, err := range errs {
+				var parseErr *ParseError
+				Expect(errors.As(err, &parseErr)).To(BeTrue())
+				reasons[index] = parseErr.Reason
+			}
+			Expect(reasons).To(Equal([]error{ErrUnexpectedCharacter, ErrInvalidCodeRange, ErrUnexpectedCharacter, ErrUnclosedGroup}))
+			Expect(errs.Error()).To(ContainSubstring("offset 8"))
+
+			Expect(c.String()).To(Equal("A001,D001"))
+		})
+
+		It("Skips the whole code around an unexpected character", func() {
+			c, errs := ParseCodeListLenient("A001, A0/02, C001!, E!X!CEPT D001")
+			Expect(errs).To(HaveLen(4))
+			Expect(errs[0].(*ParseError).Token).To(Equal("/"))
+			Expect(c.String()).To(Equal("A001,D001"))
+		})
+
+		It("Returns no errors for well formed lists", func() {
+			c, errs := ParseCodeListLenient("A001..A009")
+			Expect(errs).To(BeNil())
+			Expect(c.Includes("A005")).To(BeTrue())
+		})
+
+		It("Skips groups that cannot be kept", func() {
+			c, errs := ParseCodeListLenient("B001, [A001..A010 EXCEPT [A005]], C001")
+			Expect(errs).To(HaveLen(1))
+			Expect(c.String()).To(Equal("B001,C001"))
+		})
+	})
+})
@@ -2,7 +2,6 @@ package codes
 
 import (
 	. "github.com/onsi/ginkgo/v2"
-	. "github.com/onsi/gomega"
 )
 
 var _ = Describe("CodeList", func() {
@@ -0,0 +1,156 @@
+package codes
+
+import (
+	"sort"
+	"strings"
+)
+
+// An immutable, compiled form of a CodeList for matching codes in bulk. Ranges are merged into sorted, disjoint
+// intervals and found by binary search, rather than scanned one by one as CodeList.Includes does. A CodeMatcher
+// matches exactly the codes its CodeList does and is safe for concurrent use.
+type CodeMatcher struct {
+	system         CodeSystem
+	strictMatch    bool
+	hierarchyMatch bool
+	codes          map[string]struct{}
+	intervals      map[int][]codeInterval // Keyed by code length when matching is strict, otherwise all under 0
+	prefixes       map[string]struct{}    // The bases of patterns that only have a * wildcard
+	patterns       []codePattern          // Patterns with ? wildcards
+	except         *CodeMatcher
+}
+
+type codeInterval struct {
+	begin string
+	end   string
+}
+
+// Compile builds a CodeMatcher from the list. Later changes to the list do not affect the matcher.
+func (cc *CodeList) Compile() *CodeMatcher {
+	return cc.compile(false)
+}
+
+// Compile for a list that matches descendants as well if it or a list it is the except list of uses hierarchy matching
+func (cc *CodeList) compile(hierarchy bool) *CodeMatcher {
+	matcher := &CodeMatcher{
+		system:         cc.system,
+		strictMatch:    cc.strictMatch,
+		hierarchyMatch: hierarchy || cc.hierarchyMatch,
+		codes:          make(map[string]struct{}, len(cc.codes)),
+		intervals:      make(map[int][]codeInterval),
+		prefixes:       make(map[string]struct{}),
+	}
+
+	for code := range cc.codes {
+		matcher.codes[code] = struct{}{}
+	}
+
+	for _, cr := range cc.codeRanges {
+		key := matcher.intervalKey(cr.begin)
+		matcher.intervals[key] = append(matcher.intervals[key], codeInterval{begin: cr.begin, end: cr.end})
+	}
+	for key, intervals := range matcher.intervals {
+		matcher.intervals[key] = mergeCodeIntervals(intervals)
+	}
+
+	for _, pattern := range cc.patterns {
+		if pattern.isPrefix() && !strings.ContainsRune(pattern.text, codePatternAnyCharacter) {
+			matcher.prefixes[pattern.base()] = struct{}{}
+		} else {
+			matcher.patterns = append(matcher.patterns, pattern)
+		}
+	}
+
+	if cc.except != nil {
+		matcher.except = cc.except.compile(matcher.hierarchyMatch)
+	}
+	return matcher
+}
+
+func (m *CodeMatcher) intervalKey(code string) int {
+	if m.strictMatch {
+		return len(code)
+	}
+	return 0
+}
+
+// Sorts intervals and merges those that overlap, so at most one interval can contain any code
+func mergeCodeIntervals(intervals []codeInterval) []codeInterval {
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].begin < intervals[j].begin })
+
+	merged := make([]codeInterval, 0, len(intervals))
+	for _, interval := range intervals {
+		if last := len(merged) - 1; last >= 0 && interval.begin <= merged[last].end {
+			if interval.end > merged[last].end {
+				merged[last].end = interval.end
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+	return merged
+}
+
+func (m *CodeMatcher) Includes(code string) bool {
+	if m.except != nil && m.except.Includes(code) {
+		return false
+	}
+
+	code = NormalizeCode(m.system, code)
+	if m.includesNormalized(code) {
+		return true
+	}
+	if m.hierarchyMatch {
+		for _, ancestor := range icd10cmAncestorCodes(m.system, code) {
+			if m.includesNormalized(ancestor) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *CodeMatcher) includesNormalized(code string) bool {
+	if _, present := m.codes[code]; present {
+		return true
+	}
+
+	intervals := m.intervals[m.intervalKey(code)]
+	index := sort.Search(len(intervals), func(i int) bool { return intervals[i].end >= code })
+	if index < len(intervals) && intervals[index].begin <= code {
+		return true
+	}
+
+	return m.matchesPattern(code)
+}
+
+func (m *CodeMatcher) matchesPattern(code string) bool {
+	for length := 1; length <= len(code) && len(m.prefixes) > 0; length++ {
+		if _, present := m.prefixes[code[:length]]; present {
+			return true
+		}
+	}
+	for _, pattern := range m.patterns {
+		if pattern.matches(code) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *CodeMatcher) HasAny(codes ...string) bool {
+	for _, code := range codes {
+		if m.Includes(code) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *CodeMatcher) HasAll(codes ...string) bool {
+	for _, code := range codes {
+		if !m.Includes(code) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package codes
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+// A value set of a few thousand ranges and codes, in the spirit of the HEDIS diagnosis value sets
+func largeCodeList(random *rand.Rand) *CodeList {
+	entries := make([]string, 0, 4000)
+	for i := 0; i < 2000; i++ {
+		letter := string(rune('A' + random.Intn(26)))
+		begin := random.Intn(900)
+		entries = append(entries, fmt.Sprintf("%s%03d..%s%03d", letter, begin, letter, begin+random.Intn(99)))
+		entries = append(entries, fmt.Sprintf("%s%04d", letter, random.Intn(10000)))
+	}
+	return ParseCodeList(strings.Join(entries, ",")).Except(ParseCodeList("A100..A199, B5000"))
+}
+
+func randomCodes(random *rand.Rand, count int) []string {
+	codes := make([]string, count)
+	for i := range codes {
+		codes[i] = fmt.Sprintf("%c%0*d", 'A'+random.Intn(26), 3+random.Intn(2), random.Intn(10000))
+	}
+	return codes
+}
+
+var _ = Describe("CodeMatcher", func() {
+
+	It("Matches like the CodeList it was compiled from", func() {
+		random := rand.New(rand.NewSource(42))
+		for _, list := range []*CodeList{
+			largeCodeList(random),
+			ParseCodeList("A01..A50, A20..A99, B1, code12..code20 EXCEPT [A33, A40..A45]").WithStrictMatching(),
+			ParseCodeList("V90..V99, E11.0..E11.9"),
+			ParseCodeListFor(CODE_SYSTEM_ICD10_DIAG, "E11.0..E11.9 EXCEPT [E11.5]"),
+		} {
+			matcher := list.Compile()
+			for _, code := range append(randomCodes(random, 5000), "V90.00", "V99.1", "code125", "A33", "A44", "E11.4", "E11.5", "e11.6") {
+				Expect(matcher.Includes(code)).To(Equal(list.Includes(code)), code)
+			}
+		}
+	})
+
+	It("Supports strict matching", func() {
+		matcher := ParseCodeList("code12..code20").WithStrictMatching().Compile()
+		Expect(matcher.Includes("code17")).To(BeTrue())
+		Expect(matcher.Includes("code125")).To(BeFalse())
+	})
+
+	It("Is a CodeFinder", func() {
+		var finder CodeFinder = ParseCodeList("A001..A010").Compile()
+		Expect(finder.HasAny("B001", "A005")).To(BeTrue())
+		Expect(finder.HasAll("A001", "A011")).To(BeFalse())
+		Expect(finder.HasAll("A001", "A010")).To(BeTrue())
+	})
+
+	It("Is unaffected by later changes to the list", func() {
+		list := ParseCodeList("A001")
+		matcher := list.Compile()
+		list.WithStrictMatching().codes["A002"] = true
+		Expect(matcher.Includes("A002")).To(BeFalse())
+	})
+})
+
+func benchmarkIncludes(b *testing.B, finder CodeFinder) {
+	codes := randomCodes(rand.New(rand.NewSource(7)), 10000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		finder.HasAny(codes[i%len(codes)])
+	}
+}
+
+func BenchmarkCodeListIncludes(b *testing.B) {
+	benchmarkIncludes(b, largeCodeList(rand.New(rand.NewSource(42))))
+}
+
+func BenchmarkCodeMatcherIncludes(b *testing.B) {
+	benchmarkIncludes(b, largeCodeList(rand.New(rand.NewSource(42))).Compile())
+}
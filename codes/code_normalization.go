@@ -0,0 +1,58 @@
+package codes
+
+import "strings"
+
+// Reduces an upper-cased, trimmed code to the form used for matching within a code system
+type CodeNormalizer func(code string) string
+
+// The normalizers of the built in code systems, loaded into DefaultCodeSystemRegistry
+var codeNormalizers = map[CodeSystem]CodeNormalizer{
+	CODE_SYSTEM_ICD9_DIAG:    removeDots,
+	CODE_SYSTEM_ICD9_PROC:    removeDots,
+	CODE_SYSTEM_ICD10_DIAG:   removeDots,
+	CODE_SYSTEM_ICD10_PROC:   removeDots,
+	CODE_SYSTEM_NDC:          normalizeNdc,
+	CODE_SYSTEM_CPT:          normalizeProcedureCode,
+	CODE_SYSTEM_CPT2:         normalizeProcedureCode,
+	CODE_SYSTEM_HCPCS:        normalizeProcedureCode,
+	CODE_SYSTEM_TYPE_OF_BILL: normalizeTypeOfBill,
+}
+
+// NormalizeCode converts a code to the canonical form for its code system, so that "E11.9" and "E119" are the same
+// ICD-10-CM code. Codes without a code system are only upper-cased, as CodeList always has.
+func NormalizeCode(system CodeSystem, code string) string {
+	code = strings.ToUpper(code)
+	if system == "" {
+		return code
+	}
+
+	code = strings.TrimSpace(code)
+	if normalizer := DefaultCodeSystemRegistry.normalizer(system); normalizer != nil {
+		return normalizer(code)
+	}
+	return code
+}
+
+func removeDots(code string) string {
+	return strings.ReplaceAll(code, ".", "")
+}
+
+// Converts NDCs to the 11 digit HIPAA form. Unhyphenated 10 digit NDCs are ambiguous and are left unchanged; codes
+// that cannot be parsed as NDCs only lose their hyphens.
+func normalizeNdc(code string) string {
+	if ndc, err := ParseNDC(code); err == nil {
+		return ndc.HIPAA()
+	}
+	return strings.ReplaceAll(code, "-", "")
+}
+
+// CPT and HCPCS codes are always five characters; anything after them is a modifier
+func normalizeProcedureCode(code string) string {
+	if fields := strings.FieldsFunc(code, func(r rune) bool { return r == '-' || r == ' ' || r == '\t' }); len(fields) > 0 {
+		code = fields[0]
+	}
+	if len(code) > 5 {
+		code = code[:5]
+	}
+	return code
+}
@@ -0,0 +1,118 @@
+package codes
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("Code Normalization", func() {
+
+	Context("NormalizeCode", func() {
+		It("Only upper-cases codes without a code system", func() {
+			Expect(NormalizeCode("", "e11.9")).To(Equal("E11.9"))
+		})
+
+		It("Removes ICD dots", func() {
+			Expect(NormalizeCode(CODE_SYSTEM_ICD10_DIAG, " e11.9 ")).To(Equal("E119"))
+			Expect(NormalizeCode(CODE_SYSTEM_ICD9_DIAG, "250.00")).To(Equal("25000"))
+			Expect(NormalizeCode(CODE_SYSTEM_ICD9_PROC, "81.54")).To(Equal("8154"))
+		})
+
+		It("Pads hyphenated NDCs to 5-4-2", func() {
+			Expect(NormalizeCode(CODE_SYSTEM_NDC, "0002-3227-30")).To(Equal("00002322730"))
+			Expect(NormalizeCode(CODE_SYSTEM_NDC, "50090-347-00")).To(Equal("50090034700"))
+			Expect(NormalizeCode(CODE_SYSTEM_NDC, "50090-3470-1")).To(Equal("50090347001"))
+			Expect(NormalizeCode(CODE_SYSTEM_NDC, "00002-3227-30")).To(Equal("00002322730"))
+			Expect(NormalizeCode(CODE_SYSTEM_NDC, "00002322730")).To(Equal("00002322730"))
+			Expect(NormalizeCode(CODE_SYSTEM_NDC, "0002322730")).To(Equal("0002322730"))
+		})
+
+		It("Trims CPT and HCPCS modifiers", func() {
+			Expect(NormalizeCode(CODE_SYSTEM_CPT, " 99213 ")).To(Equal("99213"))
+			Expect(NormalizeCode(CODE_SYSTEM_CPT, "99213-25")).To(Equal("99213"))
+			Expect(NormalizeCode(CODE_SYSTEM_CPT, "27447 RT")).To(Equal("27447"))
+			Expect(NormalizeCode(CODE_SYSTEM_HCPCS, "g0439gt")).To(Equal("G0439"))
+			Expect(NormalizeCode(CODE_SYSTEM_CPT2, "3044F")).To(Equal("3044F"))
+		})
+
+		It("Trims codes of other systems", func() {
+			Expect(NormalizeCode(CODE_SYSTEM_LOINC, " 4548-4 ")).To(Equal("4548-4"))
+		})
+	})
+
+	Context("CodeList", func() {
+		It("Normalizes code list input and matched codes alike", func() {
+			c := ParseCodeListFor(CODE_SYSTEM_ICD10_DIAG, "E11.9, E10.10..E10.19")
+			Expect(c.System()).To(Equal(CODE_SYSTEM_ICD10_DIAG))
+			Expect(c.Includes("E11.9")).To(BeTrue())
+			Expect(c.Includes("E119")).To(BeTrue())
+			Expect(c.Includes("e10.15")).To(BeTrue())
+			Expect(c.HasAny("E11.8", "E10.11")).To(BeTrue())
+			Expect(c.HasAll("E119", "E1012")).To(BeTrue())
+			Expect(c.String()).To(Equal("E1010..E1019,E119"))
+		})
+
+		It("Keeps hyphens as part of the code", func() {
+			ndc := ParseCodeListFor(CODE_SYSTEM_NDC, "0002-3227-30 50090-347-00")
+			Expect(ndc.Includes("00002322730")).To(BeTrue())
+			Expect(ndc.Includes("50090-0347-00")).To(BeTrue())
+
+			loinc := ParseCodeListFor(CODE_SYSTEM_LOINC, "4548-4, 17856-6")
+			Expect(loinc.Includes("4548-4")).To(BeTrue())
+			Expect(loinc.Includes("4548")).To(BeFalse())
+		})
+
+		It("Matches procedures with modifiers", func() {
+			c := ParseCodeListFor(CODE_SYSTEM_CPT, "99201..99215")
+			Expect(c.Includes("99213-25")).To(BeTrue())
+		})
+
+		It("Normalizes range bounds before checking their lengths", func() {
+			_, err := TryParseCodeListFor(CODE_SYSTEM_ICD10_DIAG, "E11.0..E119")
+			Expect(err).To(BeNil())
+		})
+
+		It("Keeps the code system through Merge and Except", func() {
+			c := ParseCodeListFor(CODE_SYSTEM_ICD10_DIAG, "E11.0..E11.9").Except(ParseCodeListFor(CODE_SYSTEM_ICD10_DIAG, "E11.5"))
+			Expect(c.System()).To(Equal(CODE_SYSTEM_ICD10_DIAG))
+			Expect(c.Includes("E11.5")).To(BeFalse())
+			Expect(c.Includes("E11.4")).To(BeTrue())
+
+			Expect(ParseCodeList("A1").Merge(c).System()).To(Equal(CODE_SYSTEM_ICD10_DIAG))
+		})
+
+		It("Matches except lists as lists of the outer list's code system", func() {
+			c := ParseCodeListFor(CODE_SYSTEM_ICD10_DIAG, "E11.0..E11.9").Except(ParseCodeList("E11.5"))
+			Expect(c.Includes("E115")).To(BeFalse())
+			Expect(c.Includes("E11.5")).To(BeFalse())
+			Expect(c.Compile().Includes("E115")).To(BeFalse())
+			Expect(c.Match("E115").Included()).To(BeFalse())
+			Expect(c.Includes("E114")).To(BeTrue())
+		})
+
+		It("Normalizes the entries of a list without a code system merged into one with", func() {
+			c := ParseCodeList("E11.5").Merge(ParseCodeListFor(CODE_SYSTEM_ICD10_DIAG, "E10.9"))
+			Expect(c.Includes("E115")).To(BeTrue())
+			Expect(c.String()).To(Equal("E109,E115"))
+		})
+
+		It("Matches whatever either list matched after a merge", func() {
+			icd := ParseCodeListFor(CODE_SYSTEM_ICD10_DIAG, "E11.9")
+			plain := ParseCodeList("E10.9, 99213")
+			merged := icd.Merge(plain)
+			for _, code := range []string{"E11.9", "E119", "E10.9", "E109", "99213"} {
+				Expect(merged.Includes(code)).To(BeTrue(), code)
+			}
+		})
+
+		It("Rejects merging lists of different code systems", func() {
+			icd := ParseCodeListFor(CODE_SYSTEM_ICD10_DIAG, "E11.9")
+			_, err := icd.TryMerge(ParseCodeListFor(CODE_SYSTEM_CPT, "99213"))
+			Expect(err).To(Equal(ErrMixedCodeSystems))
+			Expect(func() { icd.Merge(ParseCodeListFor(CODE_SYSTEM_CPT, "99213")) }).To(Panic())
+
+			systems := NewSystemCodeList(map[CodeSystem]*CodeList{CODE_SYSTEM_ICD10_DIAG: icd, CODE_SYSTEM_CPT: ParseCodeListFor(CODE_SYSTEM_CPT, "99213")})
+			Expect(systems.HasAny(CodedValue{System: CODE_SYSTEM_ICD10_DIAG, Code: "E11.9"})).To(BeTrue())
+			Expect(systems.HasAny(CodedValue{System: CODE_SYSTEM_CPT, Code: "99213-25"})).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,98 @@
+package codes
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidCodePattern = errors.New("A code pattern's * wildcard must come last, after at least one character")
+)
+
+// Code lists can hold patterns as well as codes and ranges. A * (or %) at the end of a pattern matches any further
+// characters, so E11* matches E11, E11.9 and E1165. A ? (or _, or a lowercase x ending an otherwise upper case code)
+// matches exactly one character, so Z79.4x matches Z79.40 through Z79.4Z. An x anywhere else, as in T15.0xxA or 12x,
+// is part of the code. Patterns define the length of the codes
+// they match, so strict matching does not change them. String writes patterns with * and ?.
+const (
+	codePatternAnyCharacter = '?'
+	codePatternAnySuffix    = '*'
+)
+
+type codePattern struct {
+	text string // Upper case and normalized, with ? and * as the wildcards
+}
+
+// Reports whether a code list token, as written, is a pattern
+func isCodePattern(token string) bool {
+	return strings.ContainsAny(token, "*%?_") || trailingWildcards(token) > 0
+}
+
+// The number of lowercase x's ending the token that stand for any character: none unless the rest of the token is
+// upper case and has a letter in it
+func trailingWildcards(token string) int {
+	base := strings.TrimRight(token, "x")
+	if base == token || base != strings.ToUpper(base) || strings.IndexFunc(base, func(r rune) bool { return r >= 'A' && r <= 'Z' }) < 0 {
+		return 0
+	}
+	return len(token) - len(base)
+}
+
+func newCodePattern(system CodeSystem, token string) (codePattern, error) {
+	text := strings.NewReplacer("%", "*", "_", "?").Replace(token)
+	if count := trailingWildcards(text); count > 0 {
+		text = text[:len(text)-count] + strings.Repeat(string(codePatternAnyCharacter), count)
+	}
+	text = NormalizeCode(system, text)
+
+	if index := strings.IndexRune(text, codePatternAnySuffix); index == 0 || (index > 0 && index != len(text)-1) {
+		return codePattern{}, ErrInvalidCodePattern
+	}
+	if text == "" {
+		return codePattern{}, ErrBlankCode
+	}
+	return codePattern{text: text}, nil
+}
+
+// The characters the pattern fixes, without its * wildcard
+func (p codePattern) base() string {
+	return strings.TrimSuffix(p.text, string(codePatternAnySuffix))
+}
+
+func (p codePattern) isPrefix() bool {
+	return strings.HasSuffix(p.text, string(codePatternAnySuffix))
+}
+
+// matches reports whether an already normalized code matches the pattern
+func (p codePattern) matches(code string) bool {
+	base := p.base()
+	if len(code) < len(base) || (!p.isPrefix() && len(code) != len(base)) {
+		return false
+	}
+	for i := 0; i < len(base); i++ {
+		if base[i] != codePatternAnyCharacter && base[i] != code[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Appends the patterns of other that are not already in patterns
+func mergeCodePatterns(patterns, other []codePattern) []codePattern {
+	merged := append(make([]codePattern, 0, len(patterns)+len(other)), patterns...)
+	for _, pattern := range other {
+		if !containsCodePattern(merged, pattern) {
+			merged = append(merged, pattern)
+		}
+	}
+	return merged
+}
+
+func containsCodePattern(patterns []codePattern, pattern codePattern) bool {
+	for _, p := range patterns {
+		if p == pattern {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package codes
+
+import (
+	"math"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("Code Patterns", func() {
+
+	It("Matches prefixes", func() {
+		for _, list := range []*CodeList{ParseCodeList("E11*"), ParseCodeList("E11%")} {
+			Expect(list.Includes("E11")).To(BeTrue())
+			Expect(list.Includes("E11.65")).To(BeTrue())
+			Expect(list.Includes("e119")).To(BeTrue())
+			Expect(list.Includes("E1")).To(BeFalse())
+			Expect(list.Includes("E12")).To(BeFalse())
+		}
+	})
+
+	It("Matches single characters", func() {
+		for _, list := range []*CodeList{ParseCodeList("Z79.4x"), ParseCodeList("Z79.4?"), ParseCodeList("Z79.4_")} {
+			Expect(list.Includes("Z79.4")).To(BeFalse())
+			Expect(list.Includes("Z79.40")).To(BeTrue())
+			Expect(list.Includes("Z79.4A")).To(BeTrue())
+			Expect(list.Includes("Z79.401")).To(BeFalse())
+		}
+	})
+
+	It("Keeps a lowercase x literal in lowercase codes", func() {
+		list := ParseCodeList("codex")
+		Expect(list.Includes("CODEX")).To(BeTrue())
+		Expect(list.Includes("CODEY")).To(BeFalse())
+	})
+
+	It("Keeps a lowercase x literal before the end of a code", func() {
+		list := ParseCodeList("T15.0xxA")
+		Expect(list.Includes("T15.0XXA")).To(BeTrue())
+		Expect(list.Includes("T15012A")).To(BeFalse())
+		Expect(ParseCodeList("12x").String()).To(Equal("12X"))
+		Expect(ParseCodeList("Z79.4xx").String()).To(Equal("Z79.4??"))
+	})
+
+	It("Combines patterns with codes and ranges", func() {
+		list := ParseCodeList("I10, E10..E11, E11.6* EXCEPT [E11.65]")
+		Expect(list.HasAll("I10", "E10.1", "E11.6", "E11.69")).To(BeTrue())
+		Expect(list.Includes("E11.65")).To(BeFalse())
+		Expect(list.String()).To(Equal("E10..E11,E11.6*,I10 EXCEPT [E11.65]"))
+	})
+
+	It("Ignores strict matching", func() {
+		list := ParseCodeList("E11*, A0?").WithStrictMatching()
+		Expect(list.HasAll("E11.65", "A01")).To(BeTrue())
+		Expect(list.Includes("A012")).To(BeFalse())
+	})
+
+	It("Normalizes patterns for the list's code system", func() {
+		list := ParseCodeListFor(CODE_SYSTEM_ICD10_DIAG, "E11.6*")
+		Expect(list.Includes("E11.65")).To(BeTrue())
+		Expect(list.String()).To(Equal("E116*"))
+	})
+
+	It("Rejects misplaced wildcards", func() {
+		for _, input := range []string{"E*11", "*", "E11*..E12*"} {
+			_, err := TryParseCodeList(input)
+			Expect(err).To(Equal(ErrInvalidCodePattern), input)
+		}
+	})
+
+	It("Merges without duplicates", func() {
+		merged := ParseCodeList("E11*, A0?").Merge(ParseCodeList("E11%, B1"))
+		Expect(merged.String()).To(Equal("A0?,B1,E11*"))
+		Expect(merged.Includes("E11.9")).To(BeTrue())
+	})
+
+	It("Survives the encodings", func() {
+		list := ParseCodeList("A01, E11* EXCEPT [E11.6?]")
+
+		data, err := list.MarshalJSON()
+		Expect(err).To(BeNil())
+		Expect(string(data)).To(ContainSubstring(`"patterns":["E11*"]`))
+		decoded := &CodeList{}
+		Expect(decoded.UnmarshalJSON(data)).To(Succeed())
+		Expect(decoded.String()).To(Equal(list.String()))
+
+		binary, err := list.MarshalBinary()
+		Expect(err).To(BeNil())
+		decoded = &CodeList{}
+		Expect(decoded.UnmarshalBinary(binary)).To(Succeed())
+		Expect(decoded.String()).To(Equal(list.String()))
+		Expect(decoded.Includes("E11.65")).To(BeFalse())
+	})
+
+	It("Takes part in the set model as the codes it matches", func() {
+		list := ParseCodeList("Z79.4?")
+		Expect(list.Count()).To(Equal(36))
+		Expect(list.Equal(ParseCodeList("Z79.4?, Z79.40"))).To(BeTrue())
+		Expect(list.IsSubsetOf(ParseCodeList("Z79.4*"))).To(BeTrue())
+		Expect(ParseCodeList("A?1").Count()).To(Equal(36))
+		Expect(ParseCodeList("E11*").Equal(ParseCodeList("E11"))).To(BeFalse())
+		Expect(ParseCodeList("E11.9").IsSubsetOf(ParseCodeList("E11*"))).To(BeTrue())
+		Expect(ParseCodeList("E11.0..E11.9").IsSubsetOf(ParseCodeList("E11*"))).To(BeTrue())
+		Expect(ParseCodeList("E11*").Intersect(ParseCodeList("E1?.6*")).String()).To(Equal("E11.6*"))
+	})
+
+	It("Counts wildcards without expanding them", func() {
+		Expect(ParseCodeList("????1").Count()).To(Equal(36 * 36 * 36 * 36))
+		Expect(ParseCodeList("????1 EXCEPT [A???1]").Count()).To(Equal(35 * 36 * 36 * 36))
+
+		codes, err := ParseCodeList("A?1, A01").Expand()
+		Expect(err).To(BeNil())
+		Expect(codes).To(HaveLen(36))
+		Expect(codes[:3]).To(Equal([]string{"A01", "A11", "A21"}))
+	})
+
+	It("Expands a * pattern to the codes that start with its base", func() {
+		list := ParseCodeList("E11*")
+		Expect(list.Count()).To(Equal(math.MaxInt))
+		_, err := list.Expand()
+		Expect(err).To(Equal(ErrExpansionTooLarge))
+
+		codes := make([]string, 0)
+		for code := range list.Codes() {
+			if codes = append(codes, code); len(codes) == 3 {
+				break
+			}
+		}
+		Expect(codes).To(Equal([]string{"E11", "E110", "E111"}))
+	})
+
+	It("Is matched by CodeMatcher and CodeListIndex", func() {
+		lists := map[string]*CodeList{"Prefix": ParseCodeList("E11*"), "Single": ParseCodeList("E11.?")}
+		for _, code := range []string{"E11", "E11.6", "E11.65", "E12"} {
+			Expect(lists["Prefix"].Compile().Includes(code)).To(Equal(lists["Prefix"].Includes(code)))
+			Expect(lists["Single"].Compile().Includes(code)).To(Equal(lists["Single"].Includes(code)))
+		}
+		Expect(NewCodeListIndex(lists).Lookup("E11.6")).To(Equal([]string{"Prefix", "Single"}))
+		Expect(NewCodeListIndex(lists).Lookup("E11.65")).To(Equal([]string{"Prefix"}))
+	})
+})
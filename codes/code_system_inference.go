@@ -0,0 +1,106 @@
+package codes
+
+import (
+	"sort"
+	"strings"
+)
+
+// A code system a code could belong to, with a confidence between 0 and 1
+type CodeSystemCandidate struct {
+	System     CodeSystem
+	Confidence float64
+}
+
+// How sure a shape is of its code system: a distinctive shape (E11.9, 3074F) is strong, one shared with other code
+// systems (five digits) weaker
+const (
+	inferenceCertain  = 0.95
+	inferenceStrong   = 0.8
+	inferenceModerate = 0.6
+	inferenceWeak     = 0.4
+)
+
+// ICD-10-PCS codes are seven characters, starting with a section character and never using I or O
+const icd10PcsSections = "0123456789BCDFGHX"
+
+// InferCodeSystem ranks the code systems a code could belong to by the shape of the code alone, most likely first.
+// The shapes recognized are ICD-10-CM diagnoses (a letter, a digit and a letter or digit, with an optional dot),
+// CPT (five digits, or four and a T), CPT II (four digits and an F), HCPCS (a letter and four digits), NDC (11 digits,
+// or any hyphenated form), LOINC (digits, a hyphen and a valid check digit), revenue codes (three or four digits) and
+// ICD-10-PCS (seven letters and digits). Codes with no recognized shape have no candidates.
+func InferCodeSystem(code string) []CodeSystemCandidate {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	candidates := make(map[CodeSystem]float64)
+	add := func(system CodeSystem, confidence float64) {
+		if confidence > candidates[system] {
+			candidates[system] = confidence
+		}
+	}
+
+	digits := strings.Trim(code, "0123456789") == "" && code != ""
+	switch {
+	case digits && len(code) == 11:
+		add(CODE_SYSTEM_NDC, inferenceCertain)
+	case digits && len(code) == 10:
+		add(CODE_SYSTEM_NDC, inferenceWeak)
+	case digits && len(code) == 5:
+		add(CODE_SYSTEM_CPT, inferenceModerate)
+	case digits && len(code) == 4:
+		if code[0] == '0' {
+			add(CODE_SYSTEM_REVENUE, inferenceStrong)
+		} else {
+			add(CODE_SYSTEM_REVENUE, inferenceWeak)
+		}
+	case digits && len(code) == 3:
+		add(CODE_SYSTEM_REVENUE, inferenceWeak)
+	}
+
+	if len(code) == 5 && strings.Trim(code[:4], "0123456789") == "" {
+		switch code[4] {
+		case 'F':
+			add(CODE_SYSTEM_CPT2, inferenceCertain)
+		case 'T':
+			add(CODE_SYSTEM_CPT, inferenceCertain)
+		}
+	}
+
+	hcpcs := len(code) == 5 && code[0] >= 'A' && code[0] <= 'Z' && strings.Trim(code[1:], "0123456789") == ""
+	if hcpcs {
+		add(CODE_SYSTEM_HCPCS, inferenceStrong)
+	}
+
+	if _, err := ParseICD10CM(code); err == nil {
+		switch {
+		case strings.Contains(code, "."):
+			add(CODE_SYSTEM_ICD10_DIAG, inferenceCertain)
+		case hcpcs:
+			add(CODE_SYSTEM_ICD10_DIAG, inferenceWeak)
+		default:
+			add(CODE_SYSTEM_ICD10_DIAG, inferenceModerate)
+		}
+	}
+
+	if len(code) == 7 && isAlphanumeric(code) && !strings.ContainsAny(code, "IO") && strings.IndexByte(icd10PcsSections, code[0]) >= 0 {
+		add(CODE_SYSTEM_ICD10_PROC, inferenceStrong)
+	}
+
+	if strings.Contains(code, "-") {
+		if IsValidLOINC(code) {
+			add(CODE_SYSTEM_LOINC, inferenceCertain)
+		} else if IsValidNDC(code) {
+			add(CODE_SYSTEM_NDC, inferenceCertain)
+		}
+	}
+
+	ranked := make([]CodeSystemCandidate, 0, len(candidates))
+	for system, confidence := range candidates {
+		ranked = append(ranked, CodeSystemCandidate{System: system, Confidence: confidence})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Confidence != ranked[j].Confidence {
+			return ranked[i].Confidence > ranked[j].Confidence
+		}
+		return ranked[i].System < ranked[j].System
+	})
+	return ranked
+}
@@ -0,0 +1,58 @@
+package codes
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("Code System Inference", func() {
+
+	systems := func(code string) []CodeSystem {
+		result := make([]CodeSystem, 0)
+		for _, candidate := range InferCodeSystem(code) {
+			result = append(result, candidate.System)
+		}
+		return result
+	}
+
+	It("Recognizes distinctive shapes", func() {
+		for code, system := range map[string]CodeSystem{
+			"E11.65":       CODE_SYSTEM_ICD10_DIAG,
+			"3074F":        CODE_SYSTEM_CPT2,
+			"0042T":        CODE_SYSTEM_CPT,
+			"00093721401":  CODE_SYSTEM_NDC,
+			"50090-347-00": CODE_SYSTEM_NDC,
+			"4548-4":       CODE_SYSTEM_LOINC,
+			"0DTJ4ZZ":      CODE_SYSTEM_ICD10_PROC,
+			"J3490":        CODE_SYSTEM_HCPCS,
+			"0450":         CODE_SYSTEM_REVENUE,
+			"99213":        CODE_SYSTEM_CPT,
+		} {
+			candidates := InferCodeSystem(code)
+			Expect(candidates).NotTo(BeEmpty(), code)
+			Expect(candidates[0].System).To(Equal(system), code)
+		}
+	})
+
+	It("Ranks every candidate for ambiguous shapes", func() {
+		Expect(systems("E1165")).To(Equal([]CodeSystem{CODE_SYSTEM_HCPCS, CODE_SYSTEM_ICD10_DIAG}))
+		Expect(systems("E119")).To(Equal([]CodeSystem{CODE_SYSTEM_ICD10_DIAG}))
+		Expect(systems("B2111ZZ")).To(Equal([]CodeSystem{CODE_SYSTEM_ICD10_PROC, CODE_SYSTEM_ICD10_DIAG}))
+	})
+
+	It("Gives confidences from most to least certain", func() {
+		candidates := InferCodeSystem("E1165")
+		Expect(candidates[0].Confidence).To(BeNumerically(">", candidates[1].Confidence))
+		Expect(InferCodeSystem(" e11.65 ")[0].Confidence).To(BeNumerically(">", candidates[0].Confidence))
+		Expect(InferCodeSystem("0450")[0].Confidence).To(BeNumerically(">", InferCodeSystem("450")[0].Confidence))
+	})
+
+	It("Requires a valid LOINC check digit", func() {
+		Expect(systems("4548-5")).NotTo(ContainElement(CODE_SYSTEM_LOINC))
+	})
+
+	It("Has no candidates for unrecognized codes", func() {
+		for _, code := range []string{"", "HELLO", "12", "E11.6.5", "0DTJ4ZO"} {
+			Expect(InferCodeSystem(code)).To(BeEmpty(), code)
+		}
+	})
+})
@@ -0,0 +1,287 @@
+package codes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	ErrInvalidCodeSystem  = errors.New("Code system definition must name a code system")
+	ErrCodeSystemConflict = errors.New("Code system identifier is already registered to another code system")
+)
+
+// Describes a code system: its display name and the identifiers it can be recognized by
+type CodeSystemDefinition struct {
+	System     CodeSystem     `json:"system"`
+	Name       string         `json:"name,omitempty"`
+	OIDs       []string       `json:"oids,omitempty"`       // The first OID is the preferred one
+	FHIRSystem string         `json:"fhirSystem,omitempty"` // Canonical FHIR Coding.system URI
+	Aliases    []string       `json:"aliases,omitempty"`    // Alternate names accepted by Parse
+	Normalizer CodeNormalizer `json:"-"`
+	Validator  CodeValidator  `json:"-"`
+}
+
+// A concurrency-safe set of code system definitions. DefaultCodeSystemRegistry starts out with the CODE_SYSTEM
+// constants and is what LookupOidCodeSystem, LookupFhirCodeSystem, ParseCodeSystem and NormalizeCode consult;
+// applications add their own code systems to it at startup. Lookups read an immutable snapshot without locking, and
+// each registration publishes a new one.
+type CodeSystemRegistry struct {
+	lock     sync.Mutex
+	snapshot atomic.Pointer[codeSystemRegistryState]
+}
+
+type codeSystemRegistryState struct {
+	definitions map[CodeSystem]*CodeSystemDefinition
+	byOid       map[string]CodeSystem
+	byFhirUri   map[string]CodeSystem
+	byName      map[string]CodeSystem
+}
+
+var DefaultCodeSystemRegistry = NewDefaultCodeSystemRegistry()
+
+func NewCodeSystemRegistry() *CodeSystemRegistry {
+	registry := &CodeSystemRegistry{}
+	registry.snapshot.Store(&codeSystemRegistryState{
+		definitions: make(map[CodeSystem]*CodeSystemDefinition),
+		byOid:       make(map[string]CodeSystem),
+		byFhirUri:   make(map[string]CodeSystem),
+		byName:      make(map[string]CodeSystem),
+	})
+	return registry
+}
+
+// Copies the state for a registration. Definitions are copied as they are changed.
+func (s *codeSystemRegistryState) clone() *codeSystemRegistryState {
+	copyIndex := func(index map[string]CodeSystem) map[string]CodeSystem {
+		result := make(map[string]CodeSystem, len(index))
+		for key, system := range index {
+			result[key] = system
+		}
+		return result
+	}
+
+	definitions := make(map[CodeSystem]*CodeSystemDefinition, len(s.definitions))
+	for system, definition := range s.definitions {
+		definitions[system] = definition
+	}
+	return &codeSystemRegistryState{definitions: definitions, byOid: copyIndex(s.byOid), byFhirUri: copyIndex(s.byFhirUri), byName: copyIndex(s.byName)}
+}
+
+// NewDefaultCodeSystemRegistry returns a registry holding the built in code systems. Built in systems may share
+// identifiers (CPT and CPT2 share an OID); the first one registered wins the reverse lookup.
+func NewDefaultCodeSystemRegistry() *CodeSystemRegistry {
+	registry := NewCodeSystemRegistry()
+
+	aliases := make(map[CodeSystem][]string)
+	for alias, system := range codeSystemAliases {
+		aliases[system] = append(aliases[system], alias)
+	}
+
+	add := func(system CodeSystem, oids []string, fhirUri string) {
+		sort.Strings(aliases[system])
+		registry.register(CodeSystemDefinition{
+			System:     system,
+			Name:       CodeSystemMap[system],
+			OIDs:       oids,
+			FHIRSystem: fhirUri,
+			Aliases:    aliases[system],
+			Normalizer: codeNormalizers[system],
+			Validator:  codeValidators[system],
+		}, false)
+	}
+
+	for _, entry := range codeSystemIdentifierTable {
+		add(entry.system, entry.oids, entry.fhirUri)
+	}
+
+	remaining := make([]CodeSystem, 0)
+	for system := range CodeSystemMap {
+		if _, ok := registry.snapshot.Load().definitions[system]; !ok {
+			remaining = append(remaining, system)
+		}
+	}
+	sort.Slice(remaining, func(i, j int) bool { return remaining[i] < remaining[j] })
+	for _, system := range remaining {
+		add(system, nil, "")
+	}
+
+	return registry
+}
+
+// Register adds a code system, or extends one already registered: OIDs and aliases are added to the existing ones,
+// while a non-empty name, FHIR URI, normalizer or validator replaces the existing value. An identifier already
+// registered to a different code system, including a new system whose CodeSystem value is another's alias, is an
+// error, and nothing is changed.
+func (r *CodeSystemRegistry) Register(definition CodeSystemDefinition) error {
+	return r.register(definition, true)
+}
+
+func (r *CodeSystemRegistry) register(definition CodeSystemDefinition, strict bool) error {
+	if definition.System == "" {
+		return ErrInvalidCodeSystem
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	state := r.snapshot.Load().clone()
+	system := definition.System
+	claim := func(index map[string]CodeSystem, key, identifier string) error {
+		if owner, exists := index[key]; key == "" || owner == system {
+			return nil
+		} else if !exists {
+			index[key] = system
+		} else if strict {
+			return fmt.Errorf("%w: '%s' belongs to %s", ErrCodeSystemConflict, identifier, owner)
+		}
+		return nil
+	}
+
+	existing := &CodeSystemDefinition{System: system}
+	if current, ok := state.definitions[system]; ok {
+		*existing = *current
+		existing.OIDs = append([]string(nil), current.OIDs...)
+		existing.Aliases = append([]string(nil), current.Aliases...)
+	}
+	state.definitions[system] = existing
+
+	if err := claim(state.byName, codeSystemKey(string(system)), string(system)); err != nil {
+		return err
+	}
+	if definition.Name != "" {
+		existing.Name = definition.Name
+		if err := claim(state.byName, codeSystemKey(definition.Name), definition.Name); err != nil {
+			return err
+		}
+	}
+	if definition.FHIRSystem != "" {
+		existing.FHIRSystem = definition.FHIRSystem
+		if err := claim(state.byFhirUri, fhirUriKey(definition.FHIRSystem), definition.FHIRSystem); err != nil {
+			return err
+		}
+	}
+	if definition.Normalizer != nil {
+		existing.Normalizer = definition.Normalizer
+	}
+	if definition.Validator != nil {
+		existing.Validator = definition.Validator
+	}
+	for _, oid := range definition.OIDs {
+		if !containsString(existing.OIDs, oid) {
+			existing.OIDs = append(existing.OIDs, oid)
+		}
+		if err := claim(state.byOid, oid, oid); err != nil {
+			return err
+		}
+	}
+	for _, alias := range definition.Aliases {
+		if !containsString(existing.Aliases, alias) {
+			existing.Aliases = append(existing.Aliases, alias)
+		}
+		if err := claim(state.byName, codeSystemKey(alias), alias); err != nil {
+			return err
+		}
+	}
+
+	r.snapshot.Store(state)
+	return nil
+}
+
+// LoadJSON registers every definition in a JSON array, such as
+// [{"system": "GPI", "name": "Medispan GPI", "oids": ["2.16.840.1.113883.6.68"], "aliases": ["Medi-Span GPI"]}]
+func (r *CodeSystemRegistry) LoadJSON(reader io.Reader) error {
+	var definitions []CodeSystemDefinition
+	if err := json.NewDecoder(reader).Decode(&definitions); err != nil {
+		return err
+	}
+
+	for _, definition := range definitions {
+		if err := r.Register(definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *CodeSystemRegistry) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return r.LoadJSON(file)
+}
+
+// Lookup returns a copy of the code system's definition
+func (r *CodeSystemRegistry) Lookup(system CodeSystem) (CodeSystemDefinition, bool) {
+	definition, ok := r.snapshot.Load().definitions[system]
+	if !ok {
+		return CodeSystemDefinition{}, false
+	}
+
+	result := *definition
+	result.OIDs = append([]string(nil), definition.OIDs...)
+	result.Aliases = append([]string(nil), definition.Aliases...)
+	return result, true
+}
+
+// Systems returns every registered code system, in sorted order
+func (r *CodeSystemRegistry) Systems() []CodeSystem {
+	definitions := r.snapshot.Load().definitions
+	systems := make([]CodeSystem, 0, len(definitions))
+	for system := range definitions {
+		systems = append(systems, system)
+	}
+	sort.Slice(systems, func(i, j int) bool { return systems[i] < systems[j] })
+	return systems
+}
+
+func (r *CodeSystemRegistry) LookupOid(oid string) CodeSystem {
+	return findCodeSystem(r.snapshot.Load().byOid, oid)
+}
+
+func (r *CodeSystemRegistry) LookupFhirSystem(uri string) CodeSystem {
+	return findCodeSystem(r.snapshot.Load().byFhirUri, fhirUriKey(uri))
+}
+
+// Parse resolves a code system by its CodeSystem value, display name or alias, ignoring case, spaces and punctuation
+func (r *CodeSystemRegistry) Parse(name string) CodeSystem {
+	return findCodeSystem(r.snapshot.Load().byName, codeSystemKey(name))
+}
+
+func findCodeSystem(index map[string]CodeSystem, key string) CodeSystem {
+	if system, ok := index[key]; ok && key != "" {
+		return system
+	}
+	return CODE_SYSTEM_UNKNOWN
+}
+
+func (r *CodeSystemRegistry) normalizer(system CodeSystem) CodeNormalizer {
+	if definition, ok := r.snapshot.Load().definitions[system]; ok {
+		return definition.Normalizer
+	}
+	return nil
+}
+
+func (r *CodeSystemRegistry) validator(system CodeSystem) CodeValidator {
+	if definition, ok := r.snapshot.Load().definitions[system]; ok {
+		return definition.Validator
+	}
+	return nil
+}
+
+func containsString(items []string, item string) bool {
+	for _, candidate := range items {
+		if candidate == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package codes
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("CodeSystemRegistry", func() {
+
+	Context("Defaults", func() {
+		registry := NewDefaultCodeSystemRegistry()
+
+		It("Contains the built in code systems", func() {
+			for system, name := range CodeSystemMap {
+				definition, ok := registry.Lookup(system)
+				Expect(ok).To(BeTrue(), string(system))
+				Expect(definition.Name).To(Equal(name))
+			}
+		})
+
+		It("Resolves names, aliases, OIDs and FHIR URIs", func() {
+			Expect(registry.Parse("SNOMED CT US Edition")).To(Equal(CODE_SYSTEM_SNOMED))
+			Expect(registry.Parse("Place of Service")).To(Equal(CODE_SYSTEM_PLACE_OF_SERVICE))
+			Expect(registry.LookupOid("2.16.840.1.113883.6.12")).To(Equal(CODE_SYSTEM_CPT))
+			Expect(registry.LookupFhirSystem("http://loinc.org")).To(Equal(CODE_SYSTEM_LOINC))
+			Expect(registry.LookupOid("2.16.840.1.113883.6.68")).To(Equal(CODE_SYSTEM_UNKNOWN))
+		})
+
+		It("Names code systems", func() {
+			Expect(CODE_SYSTEM_NDC.Name()).To(Equal("National Drug Code"))
+			Expect(CodeSystem("XYZ").Name()).To(Equal("XYZ"))
+		})
+	})
+
+	Context("Registration", func() {
+		var registry *CodeSystemRegistry
+		gpi := CodeSystem("GPI")
+
+		BeforeEach(func() {
+			registry = NewDefaultCodeSystemRegistry()
+		})
+
+		It("Adds code systems", func() {
+			err := registry.Register(CodeSystemDefinition{
+				System:  gpi,
+				Name:    "Medispan GPI",
+				OIDs:    []string{"2.16.840.1.113883.6.68"},
+				Aliases: []string{"Medi-Span Generic Product Identifier"},
+			})
+			Expect(err).To(BeNil())
+
+			Expect(registry.LookupOid("2.16.840.1.113883.6.68")).To(Equal(gpi))
+			Expect(registry.Parse("medispan gpi")).To(Equal(gpi))
+			Expect(registry.Parse("Medi-Span Generic Product Identifier")).To(Equal(gpi))
+			Expect(registry.Parse("GPI")).To(Equal(gpi))
+			Expect(registry.Systems()).To(ContainElement(gpi))
+		})
+
+		It("Extends existing code systems", func() {
+			err := registry.Register(CodeSystemDefinition{System: CODE_SYSTEM_MULTUM, OIDs: []string{"2.16.840.1.113883.6.314"}})
+			Expect(err).To(BeNil())
+
+			definition, _ := registry.Lookup(CODE_SYSTEM_MULTUM)
+			Expect(definition.Name).To(Equal("Multum"))
+			Expect(definition.OIDs).To(Equal([]string{"2.16.840.1.113883.6.314"}))
+		})
+
+		It("Registers normalizers", func() {
+			err := registry.Register(CodeSystemDefinition{System: gpi, Normalizer: func(code string) string {
+				return strings.ReplaceAll(code, "-", "")
+			}})
+			Expect(err).To(BeNil())
+			Expect(registry.normalizer(gpi)("2710-0010")).To(Equal("27100010"))
+		})
+
+		It("Registers validators", func() {
+			err := registry.Register(CodeSystemDefinition{System: gpi, Validator: numericCode(14, 14)})
+			Expect(err).To(BeNil())
+			Expect(registry.validator(gpi)("27100010")).To(Equal(ErrInvalidCodeFormat))
+			Expect(registry.validator(gpi)("27100010000310")).To(BeNil())
+			Expect(registry.validator(CODE_SYSTEM_LOINC)).NotTo(BeNil())
+		})
+
+		It("Rejects conflicting identifiers", func() {
+			err := registry.Register(CodeSystemDefinition{System: gpi, OIDs: []string{"2.16.840.1.113883.6.1"}})
+			Expect(errors.Is(err, ErrCodeSystemConflict)).To(BeTrue())
+			Expect(registry.LookupOid("2.16.840.1.113883.6.1")).To(Equal(CODE_SYSTEM_LOINC))
+
+			_, ok := registry.Lookup(gpi)
+			Expect(ok).To(BeFalse())
+
+			err = registry.Register(CodeSystemDefinition{System: gpi, Aliases: []string{"icd-10-cm"}})
+			Expect(errors.Is(err, ErrCodeSystemConflict)).To(BeTrue())
+		})
+
+		It("Rejects a new code system named like another's alias", func() {
+			err := registry.Register(CodeSystemDefinition{System: "SNOMEDCT", Name: "Local SNOMED extension"})
+			Expect(errors.Is(err, ErrCodeSystemConflict)).To(BeTrue())
+			Expect(registry.Parse("SNOMEDCT")).To(Equal(CODE_SYSTEM_SNOMED))
+			Expect(registry.Parse("Local SNOMED extension")).To(Equal(CODE_SYSTEM_UNKNOWN))
+
+			_, ok := registry.Lookup("SNOMEDCT")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("Requires a code system", func() {
+			Expect(registry.Register(CodeSystemDefinition{Name: "Nameless"})).To(Equal(ErrInvalidCodeSystem))
+		})
+
+		It("Loads definitions from JSON", func() {
+			err := registry.LoadJSON(strings.NewReader(`[
+				{"system": "GPI", "name": "Medispan GPI", "oids": ["2.16.840.1.113883.6.68"]},
+				{"system": "IMO", "name": "IMO ProblemIT", "oids": ["2.16.840.1.113883.3.247.1.1"], "aliases": ["Intelligent Medical Objects"]}
+			]`))
+			Expect(err).To(BeNil())
+			Expect(registry.LookupOid("2.16.840.1.113883.6.68")).To(Equal(gpi))
+			Expect(registry.Parse("Intelligent Medical Objects")).To(Equal(CodeSystem("IMO")))
+		})
+
+		It("Is safe for concurrent use", func() {
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer GinkgoRecover()
+					defer wg.Done()
+					system := CodeSystem(fmt.Sprintf("LOCAL%d", i))
+					Expect(registry.Register(CodeSystemDefinition{System: system, OIDs: []string{fmt.Sprintf("1.2.3.%d", i)}})).To(Succeed())
+					Expect(registry.LookupOid(fmt.Sprintf("1.2.3.%d", i))).To(Equal(system))
+					Expect(registry.Parse("LOINC")).To(Equal(CODE_SYSTEM_LOINC))
+				}(i)
+			}
+			wg.Wait()
+			Expect(registry.Systems()).To(HaveLen(len(CodeSystemMap) + 20))
+		})
+	})
+})
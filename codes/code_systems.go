@@ -1,5 +1,7 @@
 package codes
 
+import "strings"
+
 // HEDIS 2019 included these - not sure what data they come from:
 // https://srs-health.com/wp-content/uploads/2016/04/SOP-Codes-3.21.14.pdf
 
@@ -38,6 +40,7 @@ const (
 	CODE_SYSTEM_REVENUE          CodeSystem = "REV"
 	CODE_SYSTEM_TYPE_OF_BILL     CodeSystem = "TOB"
 	CODE_SYSTEM_PLACE_OF_SERVICE CodeSystem = "POS"
+	CODE_SYSTEM_DISCHARGE_STATUS CodeSystem = "DISCHARGESTATUS" // UB-04 patient discharge status
 
 	// Providers
 
@@ -67,6 +70,43 @@ var CodeSystemMap = map[CodeSystem]string{
 	CODE_SYSTEM_REVENUE:           "Revenue Code",
 	CODE_SYSTEM_TYPE_OF_BILL:      "Ttype of Bill",
 	CODE_SYSTEM_PLACE_OF_SERVICE:  "Place of Service",
+	CODE_SYSTEM_DISCHARGE_STATUS:  "Patient Discharge Status",
 	CODE_SYSTEM_TAAXONOMY:         "Provider Taxonomy",
 	CODE_SYSTEM_UNKNOWN:           "Unknown",
 }
+
+// Alternate spellings of code system names seen in published value set files (HEDIS VSD, VSAC)
+var codeSystemAliases = map[string]CodeSystem{
+	"SNOMEDCT":          CODE_SYSTEM_SNOMED,
+	"SNOMEDCTUSEDITION": CODE_SYSTEM_SNOMED,
+	"CPTCATII":          CODE_SYSTEM_CPT2,
+	"CPTII":             CODE_SYSTEM_CPT2,
+	"ICD10":             CODE_SYSTEM_ICD10_DIAG,
+	"ICD9":              CODE_SYSTEM_ICD9_DIAG,
+	"UBREV":             CODE_SYSTEM_REVENUE,
+	"UBTOB":             CODE_SYSTEM_TYPE_OF_BILL,
+	"DRG":               CODE_SYSTEM_DRG,
+	"CPTMODIFIER":       CODE_SYSTEM_MODIFIER,
+	"NUCC":              CODE_SYSTEM_TAAXONOMY,
+	"PATIENTSTATUS":     CODE_SYSTEM_DISCHARGE_STATUS,
+}
+
+// ParseCodeSystem resolves a code system name, such as the "Code System" column of a value set file, to a CodeSystem.
+// The constant values, the display names in CodeSystemMap, common aliases and anything added to
+// DefaultCodeSystemRegistry are all recognized, ignoring case, spaces and punctuation.
+func ParseCodeSystem(name string) CodeSystem {
+	return DefaultCodeSystemRegistry.Parse(name)
+}
+
+func codeSystemKey(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		default:
+			return -1
+		}
+	}, name)
+}
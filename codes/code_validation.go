@@ -0,0 +1,264 @@
+package codes
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	kerrors "github.com/koanhealth/gotools/errors"
+)
+
+var (
+	ErrInvalidCodeFormat = errors.New("Code does not have the format of its code system")
+	ErrInvalidCheckDigit = errors.New("Code has the wrong check digit")
+)
+
+// Checks the format and any check digit of a code that has been normalized for its code system, returning
+// ErrInvalidCodeFormat or ErrInvalidCheckDigit
+type CodeValidator func(code string) error
+
+// The validators of the built in code systems, loaded into DefaultCodeSystemRegistry
+var codeValidators = map[CodeSystem]CodeValidator{
+	CODE_SYSTEM_LOINC:            validateLoinc,
+	CODE_SYSTEM_CVX:              numericCode(1, 3),
+	CODE_SYSTEM_RXNORM:           validateRxNorm,
+	CODE_SYSTEM_SNOMED:           validateSnomed,
+	CODE_SYSTEM_NDC:              numericCode(11, 11),
+	CODE_SYSTEM_ICD10_DIAG:       validateIcd10Cm,
+	CODE_SYSTEM_ICD10_PROC:       validateIcd10Pcs,
+	CODE_SYSTEM_CPT:              validateCpt,
+	CODE_SYSTEM_CPT2:             validateCpt2,
+	CODE_SYSTEM_HCPCS:            validateHcpcs,
+	CODE_SYSTEM_TYPE_OF_BILL:     validateTypeOfBill,
+	CODE_SYSTEM_REVENUE:          numericCode(4, 4),
+	CODE_SYSTEM_PLACE_OF_SERVICE: numericCode(2, 2),
+	CODE_SYSTEM_DISCHARGE_STATUS: numericCode(2, 2),
+	CODE_SYSTEM_TAAXONOMY:        validateTaxonomy,
+}
+
+// ValidateCode checks a code against the format of its code system. Codes of code systems without a validator are
+// always valid.
+func ValidateCode(system CodeSystem, code string) error {
+	validator := DefaultCodeSystemRegistry.validator(system)
+	if validator == nil {
+		return nil
+	}
+	return validator(NormalizeCode(system, code))
+}
+
+func IsValidCode(system CodeSystem, code string) bool {
+	return ValidateCode(system, code) == nil
+}
+
+// A code list entry that is not a valid code of the code system it was validated for
+type CodeValidationError struct {
+	Entry  CodeListEntry
+	Code   string // The code, or the bound of a range, that failed validation
+	Reason error  // ErrInvalidCodeFormat or ErrInvalidCheckDigit
+}
+
+func (e *CodeValidationError) Error() string {
+	if e.Entry.Offset < 0 {
+		return fmt.Sprintf("%s: '%s'", e.Reason.Error(), e.Code)
+	}
+	return fmt.Sprintf("%s: '%s' at offset %d ('%s')", e.Reason.Error(), e.Code, e.Entry.Offset, e.Entry.Source)
+}
+
+func (e *CodeValidationError) Unwrap() error {
+	return e.Reason
+}
+
+// Validate checks every code and range bound of the list, and of its except list, against the format of a code
+// system, or of the list's own code system if system is "". Every malformed entry is reported as a
+// *CodeValidationError, in the order the entries were written; patterns are not checked.
+func (cc *CodeList) Validate(system CodeSystem) kerrors.ErrorSlice {
+	if system == "" {
+		system = cc.system
+	}
+	validator := DefaultCodeSystemRegistry.validator(system)
+	if validator == nil {
+		return nil
+	}
+
+	errs := make([]*CodeValidationError, 0)
+	check := func(list *CodeList, entry string, codes ...string) {
+		for _, code := range codes {
+			if err := validator(NormalizeCode(system, code)); err != nil {
+				errs = append(errs, &CodeValidationError{Entry: *list.entry(entry), Code: code, Reason: err})
+			}
+		}
+	}
+	for list := cc; list != nil; list = list.except {
+		for code := range list.codes {
+			check(list, code, code)
+		}
+		for _, cr := range list.codeRanges {
+			check(list, cr.begin+".."+cr.end, cr.begin, cr.end)
+		}
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Entry.Offset != errs[j].Entry.Offset {
+			return errs[i].Entry.Offset < errs[j].Entry.Offset
+		}
+		return errs[i].Entry.Text < errs[j].Entry.Text
+	})
+
+	var result kerrors.ErrorSlice
+	for _, err := range errs {
+		result = append(result, err)
+	}
+	return result
+}
+
+// Codes of one to several digits, such as CVX codes (1 to 3) and revenue codes (4)
+func numericCode(minLength, maxLength int) CodeValidator {
+	return func(code string) error {
+		if len(code) < minLength || len(code) > maxLength || !isNumeric(code) {
+			return ErrInvalidCodeFormat
+		}
+		return nil
+	}
+}
+
+func isNumeric(s string) bool {
+	return s != "" && strings.Trim(s, "0123456789") == ""
+}
+
+// IsValidLOINC reports whether a code is a LOINC code: up to seven digits, a hyphen and the mod 10 check digit of the
+// digits
+func IsValidLOINC(code string) bool {
+	return validateLoinc(strings.TrimSpace(code)) == nil
+}
+
+func validateLoinc(code string) error {
+	number, check, found := strings.Cut(code, "-")
+	if !found || len(number) > 7 || len(check) != 1 || !isNumeric(number) || !isNumeric(check) {
+		return ErrInvalidCodeFormat
+	}
+	if check[0] != loincCheckDigit(number) {
+		return ErrInvalidCheckDigit
+	}
+	return nil
+}
+
+// The LOINC mod 10 check digit: digits are summed from the right, doubling every other one starting with the last
+// and adding the digits of each doubled value
+func loincCheckDigit(number string) byte {
+	sum := 0
+	for i := len(number) - 1; i >= 0; i-- {
+		digit := int(number[i] - '0')
+		if (len(number)-1-i)%2 == 0 {
+			digit *= 2
+			digit = digit/10 + digit%10
+		}
+		sum += digit
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// RxNorm concept unique identifiers (RXCUIs) are positive integers of up to eight digits
+func validateRxNorm(code string) error {
+	if len(code) > 8 || !isNumeric(code) || code[0] == '0' {
+		return ErrInvalidCodeFormat
+	}
+	return nil
+}
+
+// SNOMED CT identifiers are 6 to 18 digits without a leading zero. The last digit is a Verhoeff check digit, and the
+// two before it are the partition identifier: 0 or 1 (short or long form) and then 0, 1 or 2 (concept, description
+// or relationship).
+func validateSnomed(code string) error {
+	if len(code) < 6 || len(code) > 18 || !isNumeric(code) || code[0] == '0' {
+		return ErrInvalidCodeFormat
+	}
+	if partition := code[len(code)-3 : len(code)-1]; partition[0] > '1' || partition[1] > '2' {
+		return ErrInvalidCodeFormat
+	}
+	if !verhoeffValid(code) {
+		return ErrInvalidCheckDigit
+	}
+	return nil
+}
+
+var (
+	verhoeffMultiplication = [10][10]int{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 2, 3, 4, 0, 6, 7, 8, 9, 5},
+		{2, 3, 4, 0, 1, 7, 8, 9, 5, 6},
+		{3, 4, 0, 1, 2, 8, 9, 5, 6, 7},
+		{4, 0, 1, 2, 3, 9, 5, 6, 7, 8},
+		{5, 9, 8, 7, 6, 0, 4, 3, 2, 1},
+		{6, 5, 9, 8, 7, 1, 0, 4, 3, 2},
+		{7, 6, 5, 9, 8, 2, 1, 0, 4, 3},
+		{8, 7, 6, 5, 9, 3, 2, 1, 0, 4},
+		{9, 8, 7, 6, 5, 4, 3, 2, 1, 0},
+	}
+	verhoeffPermutation = [8][10]int{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 5, 7, 6, 2, 8, 3, 0, 9, 4},
+		{5, 8, 0, 3, 7, 9, 6, 1, 4, 2},
+		{8, 9, 1, 6, 0, 4, 3, 5, 2, 7},
+		{9, 4, 5, 3, 1, 2, 6, 8, 7, 0},
+		{4, 2, 8, 6, 5, 7, 3, 9, 0, 1},
+		{2, 7, 9, 3, 8, 0, 6, 4, 1, 5},
+		{7, 0, 4, 6, 9, 1, 3, 2, 5, 8},
+	}
+)
+
+// Reports whether the last digit of a number is its Verhoeff check digit
+func verhoeffValid(number string) bool {
+	check := 0
+	for i := 0; i < len(number); i++ {
+		digit := int(number[len(number)-1-i] - '0')
+		check = verhoeffMultiplication[check][verhoeffPermutation[i%8][digit]]
+	}
+	return check == 0
+}
+
+func validateIcd10Cm(code string) error {
+	if _, err := ParseICD10CM(code); err != nil {
+		return ErrInvalidCodeFormat
+	}
+	return nil
+}
+
+// ICD-10-PCS codes are seven letters and digits, starting with a section character and never using I or O
+func validateIcd10Pcs(code string) error {
+	if len(code) != 7 || !isAlphanumeric(code) || strings.ContainsAny(code, "IO") || strings.IndexByte(icd10PcsSections, code[0]) < 0 {
+		return ErrInvalidCodeFormat
+	}
+	return nil
+}
+
+// CPT codes are five digits, or four digits and a T for Category III codes
+func validateCpt(code string) error {
+	if len(code) != 5 || !isNumeric(code[:4]) || !(isDigit(code[4]) || code[4] == 'T') {
+		return ErrInvalidCodeFormat
+	}
+	return nil
+}
+
+// CPT II codes are four digits and an F
+func validateCpt2(code string) error {
+	if len(code) != 5 || !isNumeric(code[:4]) || code[4] != 'F' {
+		return ErrInvalidCodeFormat
+	}
+	return nil
+}
+
+// HCPCS Level II codes are a letter and four digits
+func validateHcpcs(code string) error {
+	if len(code) != 5 || code[0] < 'A' || code[0] > 'Z' || !isNumeric(code[1:]) {
+		return ErrInvalidCodeFormat
+	}
+	return nil
+}
+
+func validateTypeOfBill(code string) error {
+	if !IsValidTypeOfBill(code) {
+		return ErrInvalidCodeFormat
+	}
+	return nil
+}
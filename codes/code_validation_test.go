@@ -0,0 +1,107 @@
+package codes
+
+import (
+	"encoding/json"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("Code validation", func() {
+
+	It("Validates LOINC check digits", func() {
+		Expect(IsValidLOINC("4548-4")).To(BeTrue())
+		Expect(IsValidLOINC("17856-6")).To(BeTrue())
+		Expect(IsValidLOINC("2345-7")).To(BeTrue())
+		Expect(IsValidLOINC("2345-8")).To(BeFalse())
+		Expect(IsValidLOINC("2345")).To(BeFalse())
+		Expect(IsValidLOINC("23A5-7")).To(BeFalse())
+
+		Expect(ValidateCode(CODE_SYSTEM_LOINC, "2345-8")).To(Equal(ErrInvalidCheckDigit))
+		Expect(ValidateCode(CODE_SYSTEM_LOINC, "12345678-9")).To(Equal(ErrInvalidCodeFormat))
+		Expect(ValidateCode(CODE_SYSTEM_LOINC, "-7")).To(Equal(ErrInvalidCodeFormat))
+	})
+
+	It("Validates SNOMED CT Verhoeff check digits", func() {
+		for _, code := range []string{"44054006", "73211009", "195967001", "999000011000000103"} {
+			Expect(ValidateCode(CODE_SYSTEM_SNOMED, code)).To(BeNil(), code)
+		}
+		Expect(ValidateCode(CODE_SYSTEM_SNOMED, "44054007")).To(Equal(ErrInvalidCheckDigit))
+		Expect(ValidateCode(CODE_SYSTEM_SNOMED, "73211")).To(Equal(ErrInvalidCodeFormat))
+		Expect(ValidateCode(CODE_SYSTEM_SNOMED, "044054006")).To(Equal(ErrInvalidCodeFormat))
+		Expect(ValidateCode(CODE_SYSTEM_SNOMED, "44054036")).To(Equal(ErrInvalidCodeFormat))
+	})
+
+	It("Validates the formats of CVX, RxNorm and claim code systems", func() {
+		Expect(IsValidCode(CODE_SYSTEM_CVX, "140")).To(BeTrue())
+		Expect(IsValidCode(CODE_SYSTEM_CVX, "08")).To(BeTrue())
+		Expect(IsValidCode(CODE_SYSTEM_CVX, "1400")).To(BeFalse())
+		Expect(IsValidCode(CODE_SYSTEM_RXNORM, "860975")).To(BeTrue())
+		Expect(IsValidCode(CODE_SYSTEM_RXNORM, "0860975")).To(BeFalse())
+		Expect(IsValidCode(CODE_SYSTEM_RXNORM, "RX860975")).To(BeFalse())
+
+		Expect(IsValidCode(CODE_SYSTEM_ICD10_DIAG, "e11.65")).To(BeTrue())
+		Expect(IsValidCode(CODE_SYSTEM_ICD10_DIAG, "250.00")).To(BeFalse())
+		Expect(IsValidCode(CODE_SYSTEM_ICD10_PROC, "0DTJ4ZZ")).To(BeTrue())
+		Expect(IsValidCode(CODE_SYSTEM_ICD10_PROC, "0DTJ4ZO")).To(BeFalse())
+		Expect(IsValidCode(CODE_SYSTEM_CPT, "99213-25")).To(BeTrue())
+		Expect(IsValidCode(CODE_SYSTEM_CPT, "0042T")).To(BeTrue())
+		Expect(IsValidCode(CODE_SYSTEM_CPT, "3074F")).To(BeFalse())
+		Expect(IsValidCode(CODE_SYSTEM_CPT2, "3074F")).To(BeTrue())
+		Expect(IsValidCode(CODE_SYSTEM_HCPCS, "G0439")).To(BeTrue())
+		Expect(IsValidCode(CODE_SYSTEM_HCPCS, "99213")).To(BeFalse())
+		Expect(IsValidCode(CODE_SYSTEM_NDC, "0002-3227-30")).To(BeTrue())
+		Expect(IsValidCode(CODE_SYSTEM_NDC, "0002322730")).To(BeFalse())
+		Expect(IsValidCode(CODE_SYSTEM_TYPE_OF_BILL, "111")).To(BeTrue())
+		Expect(IsValidCode(CODE_SYSTEM_TYPE_OF_BILL, "0511")).To(BeFalse())
+		Expect(IsValidCode(CODE_SYSTEM_REVENUE, "0450")).To(BeTrue())
+		Expect(IsValidCode(CODE_SYSTEM_REVENUE, "450")).To(BeFalse())
+		Expect(IsValidCode(CODE_SYSTEM_PLACE_OF_SERVICE, "111")).To(BeFalse())
+	})
+
+	It("Accepts any code of code systems without a validator", func() {
+		Expect(ValidateCode(CODE_SYSTEM_MULTUM, "anything")).To(BeNil())
+		Expect(ValidateCode("", "anything")).To(BeNil())
+	})
+
+	Context("CodeList Validate", func() {
+		It("Reports every malformed entry in the order it was written", func() {
+			list := ParseCodeListFor(CODE_SYSTEM_LOINC, "4548-4, 4548-5, 2345-7..2345-8, 17856-6 EXCEPT [12A-4]")
+			errs := list.Validate("")
+			Expect(errs).To(HaveLen(3))
+
+			first := errs[0].(*CodeValidationError)
+			Expect(first.Code).To(Equal("4548-5"))
+			Expect(first.Entry).To(Equal(CodeListEntry{Text: "4548-5", Source: "4548-5", Offset: 8}))
+			Expect(errors.Is(first, ErrInvalidCheckDigit)).To(BeTrue())
+			Expect(first.Error()).To(Equal("Code has the wrong check digit: '4548-5' at offset 8 ('4548-5')"))
+
+			Expect(errs[1].(*CodeValidationError).Code).To(Equal("2345-8"))
+			Expect(errs[1].(*CodeValidationError).Entry.Text).To(Equal("2345-7..2345-8"))
+			Expect(errs[2].(*CodeValidationError).Code).To(Equal("12A-4"))
+			Expect(errors.Is(errs[2], ErrInvalidCodeFormat)).To(BeTrue())
+		})
+
+		It("Validates against another code system", func() {
+			list := ParseCodeList("140, 08, 1400, 33")
+			Expect(list.Validate("")).To(BeNil())
+
+			errs := list.Validate(CODE_SYSTEM_CVX)
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].(*CodeValidationError).Code).To(Equal("1400"))
+		})
+
+		It("Names the code of lists not parsed from text", func() {
+			var list CodeList
+			Expect(json.Unmarshal([]byte(`{"system": "SNOMED", "codes": ["44054006", "44054007"]}`), &list)).To(Succeed())
+
+			errs := list.Validate("")
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Error()).To(Equal("Code has the wrong check digit: '44054007'"))
+		})
+
+		It("Ignores patterns", func() {
+			Expect(ParseCodeListFor(CODE_SYSTEM_CPT, "9921*, 0042T").Validate("")).To(BeNil())
+		})
+	})
+})
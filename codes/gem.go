@@ -0,0 +1,130 @@
+package codes
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var (
+	ErrMalformedGEMFile = errors.New("Malformed GEM file")
+)
+
+// One line of a CMS General Equivalence Mappings file: a source code, a target code and the flags describing how
+// well they correspond
+type GEMMapping struct {
+	Source      string
+	Target      string // Blank for a no-map entry, which GEM files write as NoDx or NoPCS
+	Approximate bool   // The target is not an exact equivalent of the source
+	NoMap       bool   // The source has no equivalent in the target code system
+	Combination bool   // The source needs more than one target code, one from each choice list of a scenario
+	Scenario    int    // Which combination of target codes the mapping belongs to
+	ChoiceList  int    // Which part of the combination the target code can fill
+}
+
+// A General Equivalence Mapping between two code systems, such as the ICD-9-CM to ICD-10-CM diagnosis GEM
+// (2018_I9gem.txt) or its ICD-10-CM to ICD-9-CM counterpart (2018_I10gem.txt). Codes are looked up in either
+// direction: forward from a source code and backward from a target code.
+type GEM struct {
+	SourceSystem CodeSystem
+	TargetSystem CodeSystem
+	forward      map[string][]GEMMapping
+	backward     map[string][]GEMMapping
+}
+
+func LoadGEMFile(path string, source, target CodeSystem) (*GEM, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return LoadGEM(file, source, target)
+}
+
+// LoadGEM reads a GEM file: one mapping per line, as the source code, the target code and five flag digits
+// (approximate, no map, combination, scenario and choice list), separated by whitespace.
+func LoadGEM(reader io.Reader, source, target CodeSystem) (*GEM, error) {
+	gem := &GEM{
+		SourceSystem: source,
+		TargetSystem: target,
+		forward:      make(map[string][]GEMMapping),
+		backward:     make(map[string][]GEMMapping),
+	}
+
+	lines := bufio.NewScanner(reader)
+	for line := 1; lines.Scan(); line++ {
+		fields := strings.Fields(lines.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		mapping, err := parseGEMMapping(fields, source, target)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d %s", ErrMalformedGEMFile, line, err.Error())
+		}
+
+		gem.forward[mapping.Source] = append(gem.forward[mapping.Source], mapping)
+		if !mapping.NoMap {
+			gem.backward[mapping.Target] = append(gem.backward[mapping.Target], mapping)
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return nil, err
+	}
+	return gem, nil
+}
+
+func parseGEMMapping(fields []string, source, target CodeSystem) (GEMMapping, error) {
+	if len(fields) != 3 {
+		return GEMMapping{}, errors.New("must have a source code, target code and flags")
+	}
+	flags := fields[2]
+	if len(flags) != 5 || strings.Trim(flags, "0123456789") != "" || strings.Trim(flags[:3], "01") != "" {
+		return GEMMapping{}, fmt.Errorf("has invalid flags '%s'", flags)
+	}
+
+	mapping := GEMMapping{
+		Source:      NormalizeCode(source, fields[0]),
+		Approximate: flags[0] == '1',
+		NoMap:       flags[1] == '1',
+		Combination: flags[2] == '1',
+		Scenario:    int(flags[3] - '0'),
+		ChoiceList:  int(flags[4] - '0'),
+	}
+	if !mapping.NoMap {
+		mapping.Target = NormalizeCode(target, fields[1])
+	}
+	return mapping, nil
+}
+
+// Forward returns the mappings from a source code, including a no-map entry if the GEM has one
+func (gem *GEM) Forward(code string) []GEMMapping {
+	return gem.forward[NormalizeCode(gem.SourceSystem, code)]
+}
+
+// Backward returns the mappings to a target code
+func (gem *GEM) Backward(code string) []GEMMapping {
+	return gem.backward[NormalizeCode(gem.TargetSystem, code)]
+}
+
+// Translate returns the target codes of every source code the list includes. Only the source codes in the GEM are
+// considered, so the list's ranges and patterns are translated as far as the GEM knows their codes. The list is
+// matched as a list of the GEM's source code system, so 250.00 in a list without one translates like 25000.
+func (gem *GEM) Translate(list *CodeList) *CodeList {
+	list = list.forSystem(gem.SourceSystem)
+	translated := &CodeList{codes: make(map[string]bool), codeRanges: make([]codeRange, 0), strictMatch: list.strictMatch, system: gem.TargetSystem}
+	for source, mappings := range gem.forward {
+		if !list.Includes(source) {
+			continue
+		}
+		for _, mapping := range mappings {
+			if !mapping.NoMap {
+				translated.codes[mapping.Target] = true
+			}
+		}
+	}
+	return translated
+}
@@ -0,0 +1,81 @@
+package codes
+
+import (
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("GEM", func() {
+
+	// Lines from the 2018 ICD-9-CM to ICD-10-CM diagnosis GEM
+	file := `
+25000 E119 10000
+25001 E109 10000
+25002 E1165 10000
+4151  I2699 10000
+E0000 NoDx  11000
+99661 T827XXA 10111
+99661 B9562   10112
+99661 B9561   10112
+V5867 Z794  00000
+`
+	var gem *GEM
+
+	BeforeEach(func() {
+		var err error
+		gem, err = LoadGEM(strings.NewReader(file), CODE_SYSTEM_ICD9_DIAG, CODE_SYSTEM_ICD10_DIAG)
+		Expect(err).To(BeNil())
+	})
+
+	It("Maps forward with flags", func() {
+		mappings := gem.Forward("250.00")
+		Expect(mappings).To(Equal([]GEMMapping{{Source: "25000", Target: "E119", Approximate: true}}))
+
+		Expect(gem.Forward("V58.67")[0].Approximate).To(BeFalse())
+		Expect(gem.Forward("99999")).To(BeEmpty())
+	})
+
+	It("Exposes no-map entries", func() {
+		Expect(gem.Forward("E000.0")).To(Equal([]GEMMapping{{Source: "E0000", Approximate: true, NoMap: true}}))
+	})
+
+	It("Exposes combinations", func() {
+		mappings := gem.Forward("996.61")
+		Expect(mappings).To(HaveLen(3))
+		for _, mapping := range mappings {
+			Expect(mapping.Combination).To(BeTrue())
+			Expect(mapping.Scenario).To(Equal(1))
+		}
+		Expect(mappings[2].Target).To(Equal("B9561"))
+		Expect(mappings[2].ChoiceList).To(Equal(2))
+	})
+
+	It("Maps backward", func() {
+		mappings := gem.Backward("E11.9")
+		Expect(mappings).To(HaveLen(1))
+		Expect(mappings[0].Source).To(Equal("25000"))
+		Expect(gem.Backward("NoDx")).To(BeEmpty())
+	})
+
+	It("Translates code lists", func() {
+		translated := gem.Translate(ParseCodeListFor(CODE_SYSTEM_ICD9_DIAG, "250.00..250.02, E000.0, 996.61"))
+		Expect(translated.System()).To(Equal(CODE_SYSTEM_ICD10_DIAG))
+		Expect(translated.String()).To(Equal("B9561,B9562,E109,E1165,E119,T827XXA"))
+		Expect(translated.Includes("E11.65")).To(BeTrue())
+	})
+
+	It("Translates lists parsed without a code system", func() {
+		Expect(gem.Translate(ParseCodeList("250.00")).String()).To(Equal("E119"))
+		Expect(gem.Translate(ParseCodeList("250.00..250.02, E000.0, 996.61")).String()).To(Equal("B9561,B9562,E109,E1165,E119,T827XXA"))
+	})
+
+	It("Rejects malformed lines", func() {
+		for _, line := range []string{"25000 E119", "25000 E119 1000", "25000 E119 2000A", "25000 E119 20000"} {
+			_, err := LoadGEM(strings.NewReader(line), CODE_SYSTEM_ICD9_DIAG, CODE_SYSTEM_ICD10_DIAG)
+			Expect(errors.Is(err, ErrMalformedGEMFile)).To(BeTrue(), line)
+			Expect(err.Error()).To(ContainSubstring("line 1"))
+		}
+	})
+})
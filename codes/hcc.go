@@ -0,0 +1,318 @@
+package codes
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	kt "github.com/koanhealth/gotools/time"
+)
+
+var (
+	ErrMalformedHCCFile      = errors.New("Malformed HCC model file")
+	ErrInvalidHCCMember      = errors.New("HCC member must have a sex of F or M and a birth date before the scoring date")
+	ErrMissingHCCCoefficient = errors.New("HCC model has no coefficient for the member's age and sex")
+)
+
+// A segment of a CMS-HCC model, whose coefficients are the model's variables prefixed with the segment: CNA_HCC18 is
+// HCC 18 for community enrollees who are neither dual eligible nor disabled
+type HCCSegment string
+
+const (
+	HCC_SEGMENT_COMMUNITY_NONDUAL_AGED          HCCSegment = "CNA"
+	HCC_SEGMENT_COMMUNITY_NONDUAL_DISABLED      HCCSegment = "CND"
+	HCC_SEGMENT_COMMUNITY_FULL_DUAL_AGED        HCCSegment = "CFA"
+	HCC_SEGMENT_COMMUNITY_FULL_DUAL_DISABLED    HCCSegment = "CFD"
+	HCC_SEGMENT_COMMUNITY_PARTIAL_DUAL_AGED     HCCSegment = "CPA"
+	HCC_SEGMENT_COMMUNITY_PARTIAL_DUAL_DISABLED HCCSegment = "CPD"
+	HCC_SEGMENT_INSTITUTIONAL                   HCCSegment = "INS"
+)
+
+// The lower bounds of the CMS-HCC age bands; the last band is open ended
+var hccAgeBands = []int{0, 35, 45, 55, 60, 65, 70, 75, 80, 85, 90, 95}
+
+// The columns of the mapping, coefficient and hierarchy tables; the mapping table may also name its HCC column for
+// the model version
+var (
+	hccMappingColumns = map[string][]string{
+		"code": {"DIAGNOSISCODE", "ICD10CODE", "ICD10", "DX", "CODE"},
+		"hcc":  {"HCC", "CMSHCC"},
+	}
+	hccCoefficientColumns = map[string][]string{
+		"variable":    {"VARIABLE", "NAME", "FACTOR"},
+		"coefficient": {"COEFFICIENT", "VALUE", "WEIGHT", "RELATIVEFACTOR"},
+	}
+	hccHierarchyColumns = map[string][]string{
+		"hcc":   {"HCC"},
+		"drops": {"DROPS", "DROP", "HCCSTODROP", "SETTOZERO", "EXCLUDES"},
+	}
+)
+
+// A version of the CMS-HCC risk adjustment model, such as V24 or V28: which HCCs each ICD-10-CM code maps to, which
+// HCCs each HCC drops from the same member, and the coefficient of each variable of each segment
+type HCCModel struct {
+	Version      string
+	mapping      map[string][]string
+	hierarchies  map[string][]string
+	coefficients map[string]float64
+}
+
+func LoadHCCModelFiles(version, mappingPath, hierarchiesPath, coefficientsPath string) (*HCCModel, error) {
+	readers := make([]io.Reader, 0, 3)
+	for _, path := range []string{mappingPath, hierarchiesPath, coefficientsPath} {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		readers = append(readers, file)
+	}
+	return LoadHCCModel(version, readers[0], readers[1], readers[2])
+}
+
+// LoadHCCModel reads the three tables of a model version as CSV or TSV, each with a header line:
+//   - the mapping of ICD-10-CM codes to HCCs, with a diagnosis code column and an HCC column, which may be named for
+//     the version as in the CMS mapping spreadsheet ("CMS-HCC Model Category V24"). Codes with several HCCs have
+//     several rows, and codes with no HCC for the version are skipped.
+//   - the hierarchies, with an HCC column and a Drops column listing the HCCs it drops, separated by spaces or commas
+//   - the coefficients, with Variable and Coefficient columns, as in CNA_HCC18,0.302
+//
+// HCCs may be written as numbers (18) or labels (HCC18); they are labelled HCC18 throughout.
+func LoadHCCModel(version string, mapping, hierarchies, coefficients io.Reader) (*HCCModel, error) {
+	model := &HCCModel{
+		Version:      version,
+		mapping:      make(map[string][]string),
+		hierarchies:  make(map[string][]string),
+		coefficients: make(map[string]float64),
+	}
+
+	mappingColumns := map[string][]string{
+		"code": hccMappingColumns["code"],
+		"hcc":  append([]string{codeSystemKey("CMS-HCC Model Category " + version), codeSystemKey("HCC " + version)}, hccMappingColumns["hcc"]...),
+	}
+	err := readHCCTable(mapping, "mapping", mappingColumns, func(row int, field func(string) string) error {
+		code, hcc := NormalizeCode(CODE_SYSTEM_ICD10_DIAG, field("code")), hccLabel(field("hcc"))
+		if code == "" || hcc == "" {
+			return nil
+		}
+		if !containsString(model.mapping[code], hcc) {
+			model.mapping[code] = append(model.mapping[code], hcc)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readHCCTable(hierarchies, "hierarchy", hccHierarchyColumns, func(row int, field func(string) string) error {
+		hcc := hccLabel(field("hcc"))
+		if hcc == "" {
+			return fmt.Errorf("%w: hierarchy row %d is missing the HCC", ErrMalformedHCCFile, row)
+		}
+		for _, dropped := range strings.FieldsFunc(field("drops"), func(r rune) bool { return r == ' ' || r == ',' || r == ';' }) {
+			model.hierarchies[hcc] = append(model.hierarchies[hcc], hccLabel(dropped))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = readHCCTable(coefficients, "coefficient", hccCoefficientColumns, func(row int, field func(string) string) error {
+		variable := strings.ToUpper(field("variable"))
+		coefficient, err := strconv.ParseFloat(field("coefficient"), 64)
+		if variable == "" || err != nil {
+			return fmt.Errorf("%w: coefficient row %d must have a variable and a number", ErrMalformedHCCFile, row)
+		}
+		model.coefficients[variable] = coefficient
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// Reads the rows of a table with a header naming each column, skipping blank rows
+func readHCCTable(reader io.Reader, table string, columnNames map[string][]string, read func(row int, field func(string) string) error) error {
+	records, err := newDelimitedReader(reader)
+	if err != nil {
+		return err
+	}
+
+	header, err := records.Read()
+	if err == io.EOF {
+		return fmt.Errorf("%w: %s table is missing its header", ErrMalformedHCCFile, table)
+	} else if err != nil {
+		return err
+	}
+
+	columns := columnIndexes(header, columnNames)
+	for column, index := range columns {
+		if index < 0 {
+			return fmt.Errorf("%w: %s table header must name the %s column", ErrMalformedHCCFile, table, column)
+		}
+	}
+
+	for row := 1; ; row++ {
+		record, err := records.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+		field := func(column string) string {
+			if index := columns[column]; index < len(record) {
+				return strings.TrimSpace(record[index])
+			}
+			return ""
+		}
+		if err := read(row, field); err != nil {
+			return err
+		}
+	}
+}
+
+// Labels an HCC as CMS names its variables: 18, HCC18 and hcc 18 are all HCC18
+func hccLabel(hcc string) string {
+	hcc = strings.ToUpper(strings.ReplaceAll(hcc, " ", ""))
+	if hcc != "" && isNumeric(hcc) {
+		return "HCC" + hcc
+	}
+	return hcc
+}
+
+// Sorts HCC labels by number, so HCC2 comes before HCC18
+func sortHCCs(hccs []string) {
+	number := func(hcc string) int {
+		n, err := strconv.Atoi(strings.TrimPrefix(hcc, "HCC"))
+		if err != nil {
+			return -1
+		}
+		return n
+	}
+	sort.Slice(hccs, func(i, j int) bool {
+		if ni, nj := number(hccs[i]), number(hccs[j]); ni != nj {
+			return ni < nj
+		}
+		return hccs[i] < hccs[j]
+	})
+}
+
+// HCCs returns the HCCs the diagnoses map to, in order and before hierarchies are applied. Codes may have dots.
+func (m *HCCModel) HCCs(diagnoses ...string) []string {
+	hccs := make([]string, 0)
+	for _, diagnosis := range diagnoses {
+		for _, hcc := range m.mapping[NormalizeCode(CODE_SYSTEM_ICD10_DIAG, diagnosis)] {
+			if !containsString(hccs, hcc) {
+				hccs = append(hccs, hcc)
+			}
+		}
+	}
+	sortHCCs(hccs)
+	return hccs
+}
+
+// ApplyHierarchies drops each HCC that a more severe HCC in the same list drops, as when diabetes with acute
+// complications (HCC17) drops diabetes without complication (HCC19)
+func (m *HCCModel) ApplyHierarchies(hccs []string) []string {
+	dropped := make(map[string]bool)
+	for _, hcc := range hccs {
+		for _, lower := range m.hierarchies[hccLabel(hcc)] {
+			dropped[lower] = true
+		}
+	}
+
+	kept := make([]string, 0, len(hccs))
+	for _, hcc := range hccs {
+		if hcc = hccLabel(hcc); !dropped[hcc] && !containsString(kept, hcc) {
+			kept = append(kept, hcc)
+		}
+	}
+	sortHCCs(kept)
+	return kept
+}
+
+// A Medicare enrollee to be scored
+type HCCMember struct {
+	BirthDate time.Time
+	Sex       string   // F or M
+	Diagnoses []string // ICD-10-CM codes from the data collection period
+	Variables []string // Other variables of the model that apply to the member, such as OriginallyDisabled_Female
+}
+
+// A member's raw risk score and how it was reached
+type HCCScore struct {
+	AgeSexBand string             // The demographic variable, such as F70_74
+	HCCs       []string           // The member's HCCs after hierarchies were applied
+	Factors    map[string]float64 // The coefficient of each contributing variable, upper-cased and without the segment
+	Score      float64
+}
+
+// Score computes a member's raw risk score for a segment of the model: the sum of the coefficients of their age and
+// sex band, their HCCs after hierarchies are applied, and their other variables. Age is as of the given date, which
+// for CMS payment is February 1 of the payment year. HCCs and variables without a coefficient in the segment add
+// nothing to the score; a missing age and sex band is an error, since it means the coefficients are for another model.
+func (m *HCCModel) Score(member HCCMember, segment HCCSegment, asOf time.Time) (HCCScore, error) {
+	sex := strings.ToUpper(strings.TrimSpace(member.Sex))
+	if (sex != "F" && sex != "M") || member.BirthDate.After(asOf) {
+		return HCCScore{}, ErrInvalidHCCMember
+	}
+
+	score := HCCScore{
+		AgeSexBand: sex + hccAgeBand(kt.AgeAt(member.BirthDate, asOf)),
+		HCCs:       m.ApplyHierarchies(m.HCCs(member.Diagnoses...)),
+		Factors:    make(map[string]float64),
+	}
+
+	coefficient := func(variable string) (float64, bool) {
+		if segment != "" {
+			variable = strings.ToUpper(string(segment)) + "_" + variable
+		}
+		value, ok := m.coefficients[variable]
+		return value, ok
+	}
+
+	band, ok := coefficient(score.AgeSexBand)
+	if !ok {
+		return HCCScore{}, fmt.Errorf("%w: %s", ErrMissingHCCCoefficient, score.AgeSexBand)
+	}
+	score.Factors[score.AgeSexBand] = band
+	score.Score = band
+
+	for _, variable := range append(append([]string(nil), score.HCCs...), member.Variables...) {
+		variable = strings.ToUpper(strings.TrimSpace(variable))
+		if _, counted := score.Factors[variable]; counted {
+			continue
+		}
+		if value, ok := coefficient(variable); ok {
+			score.Factors[variable] = value
+			score.Score += value
+		}
+	}
+	return score, nil
+}
+
+// The age band of a demographic variable, such as 70_74, 0_34 or 95_GT
+func hccAgeBand(age int) string {
+	for i := len(hccAgeBands) - 1; i >= 0; i-- {
+		if age < hccAgeBands[i] {
+			continue
+		}
+		if i == len(hccAgeBands)-1 {
+			return fmt.Sprintf("%d_GT", hccAgeBands[i])
+		}
+		return fmt.Sprintf("%d_%d", hccAgeBands[i], hccAgeBands[i+1]-1)
+	}
+	return fmt.Sprintf("%d_%d", hccAgeBands[0], hccAgeBands[1]-1)
+}
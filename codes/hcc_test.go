@@ -0,0 +1,129 @@
+package codes
+
+import (
+	"errors"
+	"strings"
+
+	kt "github.com/koanhealth/gotools/time"
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("HCC", func() {
+
+	// Excerpts of the CMS-HCC V24 tables
+	mapping := `Diagnosis Code,Description,CMS-HCC Model Category V24,CMS-HCC Model Category V28
+E1010,Type 1 diabetes mellitus with ketoacidosis without coma,17,36
+E119,Type 2 diabetes mellitus without complications,19,38
+E1165,Type 2 diabetes mellitus with hyperglycemia,18,37
+I5022,Chronic systolic (congestive) heart failure,85,226
+I10,Essential (primary) hypertension,,
+F329,"Major depressive disorder, single episode, unspecified",,
+`
+	hierarchies := `HCC,Drops
+17,18 19
+18,19
+85,
+`
+	coefficients := `Variable,Coefficient
+CNA_F70_74,0.386
+CNA_M70_74,0.481
+CNA_F95_GT,0.819
+CNA_HCC17,0.302
+CNA_HCC18,0.302
+CNA_HCC19,0.105
+CNA_HCC85,0.331
+CNA_DIABETES_CHF,0.121
+INS_F70_74,1.062
+INS_HCC19,0.172
+`
+	asOf := kt.Date(2024, 2, 1)
+	var model *HCCModel
+
+	BeforeEach(func() {
+		var err error
+		model, err = LoadHCCModel("V24", strings.NewReader(mapping), strings.NewReader(hierarchies), strings.NewReader(coefficients))
+		Expect(err).To(BeNil())
+	})
+
+	It("Maps diagnoses to HCCs", func() {
+		Expect(model.Version).To(Equal("V24"))
+		Expect(model.HCCs("E11.65", "i5022", "I10", "E119")).To(Equal([]string{"HCC18", "HCC19", "HCC85"}))
+		Expect(model.HCCs("I10")).To(BeEmpty())
+	})
+
+	It("Maps diagnoses with the categories of the model version", func() {
+		v28, err := LoadHCCModel("V28", strings.NewReader(mapping), strings.NewReader("HCC,Drops\n"), strings.NewReader("Variable,Coefficient\n"))
+		Expect(err).To(BeNil())
+		Expect(v28.HCCs("E11.9", "I50.22")).To(Equal([]string{"HCC38", "HCC226"}))
+	})
+
+	It("Applies hierarchies", func() {
+		Expect(model.ApplyHierarchies([]string{"HCC17", "HCC18", "HCC19", "HCC85"})).To(Equal([]string{"HCC17", "HCC85"}))
+		Expect(model.ApplyHierarchies([]string{"19", "18"})).To(Equal([]string{"HCC18"}))
+		Expect(model.ApplyHierarchies([]string{"HCC19"})).To(Equal([]string{"HCC19"}))
+	})
+
+	It("Computes raw risk scores", func() {
+		member := HCCMember{
+			BirthDate: kt.Date(1952, 6, 15),
+			Sex:       "f",
+			Diagnoses: []string{"E11.65", "E11.9", "I50.22", "I10"},
+			Variables: []string{"DIABETES_CHF", "OriginallyDisabled_Female"},
+		}
+
+		score, err := model.Score(member, HCC_SEGMENT_COMMUNITY_NONDUAL_AGED, asOf)
+		Expect(err).To(BeNil())
+		Expect(score.AgeSexBand).To(Equal("F70_74"))
+		Expect(score.HCCs).To(Equal([]string{"HCC18", "HCC85"}))
+		Expect(score.Factors).To(Equal(map[string]float64{"F70_74": 0.386, "HCC18": 0.302, "HCC85": 0.331, "DIABETES_CHF": 0.121}))
+		Expect(score.Score).To(BeNumerically("~", 1.14, 1e-9))
+
+		score, err = model.Score(member, HCC_SEGMENT_INSTITUTIONAL, asOf)
+		Expect(err).To(BeNil())
+		Expect(score.Score).To(BeNumerically("~", 1.062, 1e-9))
+	})
+
+	It("Counts each variable once, whatever its case", func() {
+		member := HCCMember{BirthDate: kt.Date(1952, 6, 15), Sex: "F", Variables: []string{"diabetes_chf", "DIABETES_CHF"}}
+		score, err := model.Score(member, "cna", asOf)
+		Expect(err).To(BeNil())
+		Expect(score.Factors).To(Equal(map[string]float64{"F70_74": 0.386, "DIABETES_CHF": 0.121}))
+		Expect(score.Score).To(BeNumerically("~", 0.507, 1e-9))
+	})
+
+	It("Bands ages as of the scoring date", func() {
+		member := HCCMember{BirthDate: kt.Date(1954, 2, 1), Sex: "M"}
+		score, err := model.Score(member, HCC_SEGMENT_COMMUNITY_NONDUAL_AGED, asOf)
+		Expect(err).To(BeNil())
+		Expect(score.AgeSexBand).To(Equal("M70_74"))
+		Expect(score.Score).To(BeNumerically("~", 0.481, 1e-9))
+
+		Expect(hccAgeBand(0)).To(Equal("0_34"))
+		Expect(hccAgeBand(64)).To(Equal("60_64"))
+		Expect(hccAgeBand(95)).To(Equal("95_GT"))
+		Expect(hccAgeBand(101)).To(Equal("95_GT"))
+	})
+
+	It("Requires a coefficient for the age and sex band", func() {
+		_, err := model.Score(HCCMember{BirthDate: kt.Date(1954, 2, 2), Sex: "M"}, HCC_SEGMENT_COMMUNITY_NONDUAL_AGED, asOf)
+		Expect(errors.Is(err, ErrMissingHCCCoefficient)).To(BeTrue())
+	})
+
+	It("Rejects members without a sex or with a future birth date", func() {
+		_, err := model.Score(HCCMember{BirthDate: kt.Date(1952, 6, 15), Sex: "U"}, HCC_SEGMENT_COMMUNITY_NONDUAL_AGED, asOf)
+		Expect(err).To(Equal(ErrInvalidHCCMember))
+		_, err = model.Score(HCCMember{BirthDate: kt.Date(2025, 1, 1), Sex: "F"}, HCC_SEGMENT_COMMUNITY_NONDUAL_AGED, asOf)
+		Expect(err).To(Equal(ErrInvalidHCCMember))
+	})
+
+	It("Rejects malformed tables", func() {
+		_, err := LoadHCCModel("V24", strings.NewReader("Code,Description\nE119,Diabetes\n"), strings.NewReader(hierarchies), strings.NewReader(coefficients))
+		Expect(errors.Is(err, ErrMalformedHCCFile)).To(BeTrue())
+
+		_, err = LoadHCCModel("V24", strings.NewReader(mapping), strings.NewReader(hierarchies), strings.NewReader("Variable,Coefficient\nCNA_HCC18,high\n"))
+		Expect(errors.Is(err, ErrMalformedHCCFile)).To(BeTrue())
+
+		_, err = LoadHCCModel("V24", strings.NewReader(mapping), strings.NewReader(""), strings.NewReader(coefficients))
+		Expect(errors.Is(err, ErrMalformedHCCFile)).To(BeTrue())
+	})
+})
@@ -0,0 +1,112 @@
+package codes
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidICD10CMCode = errors.New("Invalid ICD-10-CM code")
+)
+
+// The parts of an ICD-10-CM diagnosis code. S72.001A is category S72 (fracture of femur), etiology 001 (the
+// anatomic site and other detail) and extension A (initial encounter). Codes that need a 7th character extension but
+// have fewer than six characters fill the gap with X placeholders, as in T15.0XXA.
+type ICD10CMCode struct {
+	Category  string // The three characters before the dot
+	Etiology  string // Up to three characters after the dot, including any X placeholders
+	Extension string // The 7th character, if any
+}
+
+// ParseICD10CM parses an ICD-10-CM code, with or without its dot
+func ParseICD10CM(code string) (ICD10CMCode, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if dot := strings.IndexByte(code, '.'); dot >= 0 {
+		if dot != 3 || dot == len(code)-1 {
+			return ICD10CMCode{}, ErrInvalidICD10CMCode
+		}
+		code = code[:dot] + code[dot+1:]
+	}
+
+	if len(code) < 3 || len(code) > 7 || code[0] < 'A' || code[0] > 'Z' || code[1] < '0' || code[1] > '9' {
+		return ICD10CMCode{}, ErrInvalidICD10CMCode
+	}
+	for i := 2; i < len(code); i++ {
+		if !((code[i] >= 'A' && code[i] <= 'Z') || (code[i] >= '0' && code[i] <= '9')) {
+			return ICD10CMCode{}, ErrInvalidICD10CMCode
+		}
+	}
+
+	parsed := ICD10CMCode{Category: code[:3], Etiology: code[3:min(len(code), 6)]}
+	if len(code) == 7 {
+		parsed.Extension = code[6:]
+	}
+	return parsed, nil
+}
+
+// String writes the code with its dot, as in E11.65
+func (c ICD10CMCode) String() string {
+	if c.Etiology == "" && c.Extension == "" {
+		return c.Category
+	}
+	return c.Category + "." + c.Etiology + c.Extension
+}
+
+// Code writes the code without its dot, as in E1165
+func (c ICD10CMCode) Code() string {
+	return c.Category + c.Etiology + c.Extension
+}
+
+// Parent returns the code one level up the hierarchy, skipping X placeholders: S72.001A is under S72.001, which is
+// under S72.00, while T15.0XXA is directly under T15.0. Categories have no parent.
+func (c ICD10CMCode) Parent() (ICD10CMCode, bool) {
+	switch {
+	case c.Extension != "":
+		return ICD10CMCode{Category: c.Category, Etiology: strings.TrimRight(c.Etiology, "X")}, true
+	case c.Etiology != "":
+		return ICD10CMCode{Category: c.Category, Etiology: strings.TrimRight(c.Etiology[:len(c.Etiology)-1], "X")}, true
+	default:
+		return ICD10CMCode{}, false
+	}
+}
+
+// Ancestors returns the codes above this one, nearest first and ending with its category
+func (c ICD10CMCode) Ancestors() []ICD10CMCode {
+	ancestors := make([]ICD10CMCode, 0, 4)
+	for parent, ok := c.Parent(); ok; parent, ok = parent.Parent() {
+		ancestors = append(ancestors, parent)
+	}
+	return ancestors
+}
+
+// IsDescendantOf reports whether the code falls under the other code in the hierarchy
+func (c ICD10CMCode) IsDescendantOf(other ICD10CMCode) bool {
+	for _, ancestor := range c.Ancestors() {
+		if ancestor == other {
+			return true
+		}
+	}
+	return false
+}
+
+// The ancestors of an ICD-10-CM code normalized for a code system, with and without their dots for lists written
+// either way. Codes that are not ICD-10-CM codes, and codes of any code system but ICD-10-CM or none, have none.
+func icd10cmAncestorCodes(system CodeSystem, code string) []string {
+	if system != "" && system != CODE_SYSTEM_ICD10_DIAG {
+		return nil
+	}
+	parsed, err := ParseICD10CM(code)
+	if err != nil {
+		return nil
+	}
+
+	ancestors := make([]string, 0, 8)
+	for _, ancestor := range parsed.Ancestors() {
+		for _, form := range []string{ancestor.String(), ancestor.Code()} {
+			if normalized := NormalizeCode(system, form); len(ancestors) == 0 || ancestors[len(ancestors)-1] != normalized {
+				ancestors = append(ancestors, normalized)
+			}
+		}
+	}
+	return ancestors
+}
@@ -0,0 +1,182 @@
+package codes
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("ICD-10-CM", func() {
+
+	parse := func(system CodeSystem, text string) *CodeList {
+		list, err := TryParseCodeListStrictFor(system, text)
+		Expect(err).To(BeNil(), text)
+		return list
+	}
+
+	Context("Parsing", func() {
+		It("Parses category, etiology and extension", func() {
+			code, err := ParseICD10CM("s72.001a")
+			Expect(err).To(BeNil())
+			Expect(code).To(Equal(ICD10CMCode{Category: "S72", Etiology: "001", Extension: "A"}))
+			Expect(code.String()).To(Equal("S72.001A"))
+			Expect(code.Code()).To(Equal("S72001A"))
+		})
+
+		It("Parses codes without dots", func() {
+			code, err := ParseICD10CM("E1165")
+			Expect(err).To(BeNil())
+			Expect(code).To(Equal(ICD10CMCode{Category: "E11", Etiology: "65"}))
+			Expect(code.String()).To(Equal("E11.65"))
+		})
+
+		It("Keeps X placeholders in the etiology", func() {
+			code, err := ParseICD10CM("T15.0XXA")
+			Expect(err).To(BeNil())
+			Expect(code).To(Equal(ICD10CMCode{Category: "T15", Etiology: "0XX", Extension: "A"}))
+		})
+
+		It("Parses categories", func() {
+			code, err := ParseICD10CM("M1A")
+			Expect(err).To(BeNil())
+			Expect(code.String()).To(Equal("M1A"))
+		})
+
+		It("Rejects codes that are not ICD-10-CM", func() {
+			for _, input := range []string{"", "E1", "E11.", "E1.16", "11.65", "EE1", "S72.001AB", "E11-65", "99213"} {
+				_, err := ParseICD10CM(input)
+				Expect(err).To(Equal(ErrInvalidICD10CMCode), input)
+			}
+		})
+	})
+
+	Context("Hierarchy", func() {
+		ancestors := func(input string) []string {
+			code, err := ParseICD10CM(input)
+			Expect(err).To(BeNil())
+			result := make([]string, 0)
+			for _, ancestor := range code.Ancestors() {
+				result = append(result, ancestor.String())
+			}
+			return result
+		}
+
+		It("Returns ancestors nearest first", func() {
+			Expect(ancestors("E11.65")).To(Equal([]string{"E11.6", "E11"}))
+			Expect(ancestors("S72.001A")).To(Equal([]string{"S72.001", "S72.00", "S72.0", "S72"}))
+			Expect(ancestors("E11")).To(BeEmpty())
+		})
+
+		It("Skips X placeholders", func() {
+			Expect(ancestors("T15.0XXA")).To(Equal([]string{"T15.0", "T15"}))
+			Expect(ancestors("T36.0X1A")).To(Equal([]string{"T36.0X1", "T36.0", "T36"}))
+		})
+
+		It("Reports descendants", func() {
+			code, _ := ParseICD10CM("E11.65")
+			category, _ := ParseICD10CM("E11")
+			Expect(code.IsDescendantOf(category)).To(BeTrue())
+			Expect(category.IsDescendantOf(code)).To(BeFalse())
+			Expect(code.IsDescendantOf(code)).To(BeFalse())
+		})
+	})
+
+	Context("Hierarchy matching", func() {
+		It("Matches descendants of listed codes", func() {
+			list := ParseCodeList("E11, I10").WithHierarchyMatching()
+			Expect(list.HasAll("E11", "E11.6", "E11.65", "E1165", "I10")).To(BeTrue())
+			Expect(list.HasAny("E10.9", "E1", "I11.0")).To(BeFalse())
+		})
+
+		It("Matches descendants with dotted and undotted lists", func() {
+			Expect(parse("", "HIERARCHY E11.6").Includes("E11.65")).To(BeTrue())
+			Expect(parse("", "HIERARCHY E116").Includes("E11.65")).To(BeTrue())
+			Expect(parse(CODE_SYSTEM_ICD10_DIAG, "HIERARCHY E11.6").Includes("E11.65")).To(BeTrue())
+		})
+
+		It("Only applies the ICD-10-CM hierarchy to ICD-10-CM lists", func() {
+			hcpcs := parse(CODE_SYSTEM_HCPCS, "HIERARCHY G04")
+			Expect(hcpcs.Includes("G0439")).To(BeFalse())
+			Expect(hcpcs.Compile().Includes("G0439")).To(BeFalse())
+			Expect(hcpcs.Match("G0439").Included()).To(BeFalse())
+			Expect(NewCodeListIndex(map[string]*CodeList{"Wellness": hcpcs}).Lookup("G0439")).To(BeEmpty())
+			Expect(parse("", "HIERARCHY G04").Includes("G0439")).To(BeTrue())
+		})
+
+		It("Matches descendants of ranges", func() {
+			list := parse("", "STRICT HIERARCHY E10..E11")
+			Expect(list.HasAll("E10.9", "E11.65")).To(BeTrue())
+			Expect(list.Includes("E13.0")).To(BeFalse())
+		})
+
+		It("Only matches descendants when asked", func() {
+			Expect(ParseCodeList("E11").Includes("E11.65")).To(BeFalse())
+		})
+
+		It("Excludes the descendants of the except list's codes", func() {
+			list := parse("", "HIERARCHY E11 EXCEPT [HIERARCHY E11.6]")
+			Expect(list.Includes("E11.9")).To(BeTrue())
+			Expect(list.Includes("E11.65")).To(BeFalse())
+
+			list = parse("", "HIERARCHY E11 EXCEPT [E11.6]")
+			Expect(list.Includes("E11.6")).To(BeFalse())
+			Expect(list.Includes("E11.65")).To(BeFalse())
+			Expect(list.Includes("E11.9")).To(BeTrue())
+
+			list = ParseCodeListFor(CODE_SYSTEM_ICD10_DIAG, "E11 EXCEPT [E11.5]").WithHierarchyMatching()
+			Expect(list.Includes("E11.52")).To(BeFalse())
+			Expect(list.Compile().Includes("E11.52")).To(BeFalse())
+			Expect(NewCodeListIndex(map[string]*CodeList{"Diabetes": list}).Lookup("E11.52")).To(BeEmpty())
+			Expect(list.Match("E11.52").Excluded.Ancestor).To(Equal("E115"))
+			Expect(list.Includes("E11.49")).To(BeTrue())
+		})
+
+		It("Keeps an except list's own except list matching descendants", func() {
+			list := parse("", "HIERARCHY E11 EXCEPT [E11.5 EXCEPT [E11.52]]")
+			Expect(list.Includes("E11.51")).To(BeFalse())
+			Expect(list.Includes("E11.52")).To(BeTrue())
+			Expect(list.Includes("E11.521")).To(BeTrue())
+			Expect(list.Compile().Includes("E11.521")).To(BeTrue())
+		})
+
+		It("Survives the encodings", func() {
+			list := parse("", "STRICT HIERARCHY E11 EXCEPT [HIERARCHY E11.6]")
+			text, err := list.MarshalText()
+			Expect(err).To(BeNil())
+			Expect(string(text)).To(Equal("STRICT HIERARCHY E11 EXCEPT [HIERARCHY E11.6]"))
+			decoded := &CodeList{}
+			Expect(decoded.UnmarshalText(text)).To(Succeed())
+			Expect(decoded.Includes("E11.9")).To(BeTrue())
+			Expect(decoded.Includes("E11.65")).To(BeFalse())
+
+			data, err := list.MarshalJSON()
+			Expect(err).To(BeNil())
+			Expect(string(data)).To(ContainSubstring(`"hierarchy":true`))
+			decoded = &CodeList{}
+			Expect(decoded.UnmarshalJSON(data)).To(Succeed())
+			Expect(decoded.String()).To(Equal(list.String()))
+
+			binary, err := list.MarshalBinary()
+			Expect(err).To(BeNil())
+			decoded = &CodeList{}
+			Expect(decoded.UnmarshalBinary(binary)).To(Succeed())
+			Expect(decoded.String()).To(Equal(list.String()))
+		})
+
+		It("Is matched by CodeMatcher and CodeListIndex", func() {
+			lists := map[string]*CodeList{
+				"Diabetes":     parse(CODE_SYSTEM_ICD10_DIAG, "HIERARCHY E11 EXCEPT [E11.9]"),
+				"Complicated":  parse(CODE_SYSTEM_ICD10_DIAG, "HIERARCHY E11.6"),
+				"Uncontrolled": ParseCodeListFor(CODE_SYSTEM_ICD10_DIAG, "E11.65"),
+			}
+			for _, code := range []string{"E11", "E11.65", "E11.9", "E11.649", "E12"} {
+				for _, list := range lists {
+					Expect(list.Compile().Includes(code)).To(Equal(list.Includes(code)), code)
+				}
+			}
+
+			index := NewCodeListIndex(lists)
+			Expect(index.Lookup("E11.65")).To(Equal([]string{"Complicated", "Diabetes", "Uncontrolled"}))
+			Expect(index.Lookup("E11.649")).To(Equal([]string{"Complicated", "Diabetes"}))
+			Expect(index.Lookup("E11.9")).To(BeEmpty())
+		})
+	})
+})
@@ -1,47 +1,90 @@
 package codes
 
+import "strings"
+
 // Refer to: http://www.hl7.org/OID/
+// and: https://terminology.hl7.org/external_terminologies.html
+
+// OID's seen from real data, but not identified here:
+// 2.16.840.1.113883.3.247.1.1: Intelligent Medical Objects ProblemIT
+// 2.16.840.1.113883.5.4: Act Class: https://www.hl7.org/fhir/v3/ActClass/cs.html
+// 2.16.840.1.113883.5.6: Act Class: https://www.hl7.org/fhir/v3/ActClass/cs.html
+// 2.16.840.1.113883.5.8: Act Reason
+// 2.16.840.1.113883.6.68: Medispan GPI
+// 2.16.840.1.113883.6.162: Master Drug Database
+// 2.16.840.1.113883.6.253: Medispan Drug
+
+type codeSystemIdentifiers struct {
+	system  CodeSystem
+	oids    []string // The first OID is the preferred one
+	fhirUri string
+}
+
+// The identifiers of the built in code systems, loaded into DefaultCodeSystemRegistry. Where an OID or URI is shared
+// (CPT and CPT2, ICD-9-CM diagnoses and procedures) the earlier entry wins the reverse lookup.
+var codeSystemIdentifierTable = []codeSystemIdentifiers{
+	{CODE_SYSTEM_SNOMED, []string{"2.16.840.1.113883.6.96"}, "http://snomed.info/sct"},
+	{CODE_SYSTEM_LOINC, []string{"2.16.840.1.113883.6.1"}, "http://loinc.org"},
+	{CODE_SYSTEM_RXNORM, []string{"2.16.840.1.113883.6.88"}, "http://www.nlm.nih.gov/research/umls/rxnorm"},
+	{CODE_SYSTEM_CPT, []string{"2.16.840.1.113883.6.12"}, "http://www.ama-assn.org/go/cpt"},
+	{CODE_SYSTEM_CPT2, []string{"2.16.840.1.113883.6.12"}, "http://www.ama-assn.org/go/cpt"},
+	{CODE_SYSTEM_HCPCS, []string{"2.16.840.1.113883.6.285", "2.16.840.1.113883.6.14"}, "http://www.cms.gov/Medicare/Coding/HCPCSReleaseCodeSets"},
+	{CODE_SYSTEM_NDC, []string{"2.16.840.1.113883.6.69"}, "http://hl7.org/fhir/sid/ndc"},
+	{CODE_SYSTEM_CVX, []string{"2.16.840.1.113883.12.292"}, "http://hl7.org/fhir/sid/cvx"},
+	{CODE_SYSTEM_ICD9_DIAG, []string{"2.16.840.1.113883.6.103"}, "http://hl7.org/fhir/sid/icd-9-cm"},
+	{CODE_SYSTEM_ICD9_PROC, []string{"2.16.840.1.113883.6.104"}, "http://hl7.org/fhir/sid/icd-9-cm"},
+	{CODE_SYSTEM_ICD10_DIAG, []string{"2.16.840.1.113883.6.90", "2.16.840.1.113883.6.3"}, "http://hl7.org/fhir/sid/icd-10-cm"},
+	{CODE_SYSTEM_ICD10_PROC, []string{"2.16.840.1.113883.6.4"}, "http://www.cms.gov/Medicare/Coding/ICD10"},
+	{CODE_SYSTEM_HIPPS, []string{"2.16.840.1.113883.15.4"}, "https://www.cms.gov/Medicare/Medicare-Fee-for-Service-Payment/ProspMedicareFeeSvcPmtGen/HIPPSCodes"}, // https: //oidref.com/2.16.840.1.113883.15.4
+	{CODE_SYSTEM_SOURCE_OF_PAYMENT, []string{"2.16.840.1.113883.3.221.5"}, "https://nahdo.org/sopt"},
+	{CODE_SYSTEM_DRG, nil, "https://www.cms.gov/Medicare/Medicare-Fee-for-Service-Payment/AcuteInpatientPPS/MS-DRG-Classifications-and-Software"},
+	{CODE_SYSTEM_REVENUE, []string{"2.16.840.1.113883.6.301.3"}, "https://www.nubc.org/CodeSystem/RevenueCodes"},
+	{CODE_SYSTEM_TYPE_OF_BILL, []string{"2.16.840.1.113883.6.301.1"}, "https://www.nubc.org/CodeSystem/TypeOfBill"},
+	{CODE_SYSTEM_DISCHARGE_STATUS, []string{"2.16.840.1.113883.6.301.5"}, "https://www.nubc.org/CodeSystem/PatDischargeStatus"},
+	{CODE_SYSTEM_PLACE_OF_SERVICE, []string{"2.16.840.1.113883.6.50"}, "https://www.cms.gov/Medicare/Coding/place-of-service-codes/Place_of_Service_Code_Set"},
+	{CODE_SYSTEM_TAAXONOMY, []string{"2.16.840.1.113883.6.101"}, "http://nucc.org/provider-taxonomy"},
+}
 
 func LookupOidCodeSystem(oid string) CodeSystem {
-	if oid == "" {
-		return CODE_SYSTEM_UNKNOWN
+	return DefaultCodeSystemRegistry.LookupOid(strings.TrimSpace(oid))
+}
+
+// LookupFhirCodeSystem resolves a FHIR Coding.system URI. The scheme (http or https) and a trailing slash are ignored.
+func LookupFhirCodeSystem(uri string) CodeSystem {
+	return DefaultCodeSystemRegistry.LookupFhirSystem(uri)
+}
+
+func fhirUriKey(uri string) string {
+	uri = strings.TrimSpace(uri)
+	uri = strings.TrimPrefix(uri, "http://")
+	uri = strings.TrimPrefix(uri, "https://")
+	return strings.TrimSuffix(uri, "/")
+}
+
+// OID returns the preferred OID for the code system, as used in CDA documents, or "" if it has none
+func (cs CodeSystem) OID() string {
+	if definition, ok := DefaultCodeSystemRegistry.Lookup(cs); ok && len(definition.OIDs) > 0 {
+		return definition.OIDs[0]
 	}
+	return ""
+}
+
+// OIDs returns every OID known for the code system, preferred OID first
+func (cs CodeSystem) OIDs() []string {
+	definition, _ := DefaultCodeSystemRegistry.Lookup(cs)
+	return definition.OIDs
+}
+
+// FHIRSystem returns the canonical FHIR Coding.system URI for the code system, or "" if it has none
+func (cs CodeSystem) FHIRSystem() string {
+	definition, _ := DefaultCodeSystemRegistry.Lookup(cs)
+	return definition.FHIRSystem
+}
 
-	// OID's seen from real data, but not identified here:
-	// 2.16.840.1.113883.3.247.1.1: Intelligent Medical Objects ProblemIT
-	// 2.16.840.1.113883.5.4: Act Class: https://www.hl7.org/fhir/v3/ActClass/cs.html
-	// 2.16.840.1.113883.5.6: Act Class: https://www.hl7.org/fhir/v3/ActClass/cs.html
-	// 2.16.840.1.113883.5.8: Act Reason
-	// 2.16.840.1.113883.6.68: Medispan GPI
-	// 2.16.840.1.113883.6.162: Master Drug Database
-	// 2.16.840.1.113883.6.253: Medispan Drug
-
-	switch oid {
-	case "2.16.840.1.113883.6.96":
-		return CODE_SYSTEM_SNOMED
-	case "2.16.840.1.113883.6.1":
-		return CODE_SYSTEM_LOINC
-	case "2.16.840.1.113883.6.88":
-		return CODE_SYSTEM_RXNORM
-	case "2.16.840.1.113883.6.12":
-		return CODE_SYSTEM_CPT
-	case "2.16.840.1.113883.6.285", "2.16.840.1.113883.6.14":
-		return CODE_SYSTEM_HCPCS
-	case "2.16.840.1.113883.6.69":
-		return CODE_SYSTEM_NDC
-	case "2.16.840.1.113883.12.292":
-		return CODE_SYSTEM_CVX
-	case "2.16.840.1.113883.6.104":
-		return CODE_SYSTEM_ICD9_PROC
-	case "2.16.840.1.113883.6.103":
-		return CODE_SYSTEM_ICD9_DIAG
-	case "2.16.840.1.113883.6.3", "2.16.840.1.113883.6.90":
-		return CODE_SYSTEM_ICD10_DIAG
-	case "2.16.840.1.113883.6.4":
-		return CODE_SYSTEM_ICD10_PROC
-	case "2.16.840.1.113883.15.4":
-		return CODE_SYSTEM_HIPPS // https: //oidref.com/2.16.840.1.113883.15.4
-	default:
-		return CODE_SYSTEM_UNKNOWN
+// Name returns the registered display name of the code system, or the code system itself if it has none
+func (cs CodeSystem) Name() string {
+	if definition, ok := DefaultCodeSystemRegistry.Lookup(cs); ok && definition.Name != "" {
+		return definition.Name
 	}
+	return string(cs)
 }
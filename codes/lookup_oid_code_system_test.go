@@ -2,7 +2,6 @@ package codes
 
 import (
 	. "github.com/onsi/ginkgo/v2"
-	. "github.com/onsi/gomega"
 )
 
 var _ = Describe("CodeList", func() {
@@ -17,3 +16,42 @@ var _ = Describe("CodeList", func() {
 		Expect(LookupOidCodeSystem("2.16.840.1.113883.6.1")).To(Equal(CODE_SYSTEM_LOINC))
 	})
 })
+
+var _ = Describe("Code System Identifiers", func() {
+
+	It("Looks up alternate OIDs", func() {
+		Expect(LookupOidCodeSystem("2.16.840.1.113883.6.3")).To(Equal(CODE_SYSTEM_ICD10_DIAG))
+		Expect(LookupOidCodeSystem("2.16.840.1.113883.6.90")).To(Equal(CODE_SYSTEM_ICD10_DIAG))
+		Expect(LookupOidCodeSystem("2.16.840.1.113883.6.14")).To(Equal(CODE_SYSTEM_HCPCS))
+		Expect(LookupOidCodeSystem("")).To(Equal(CODE_SYSTEM_UNKNOWN))
+	})
+
+	It("Maps code systems to OIDs", func() {
+		Expect(CODE_SYSTEM_ICD10_DIAG.OID()).To(Equal("2.16.840.1.113883.6.90"))
+		Expect(CODE_SYSTEM_ICD10_DIAG.OIDs()).To(Equal([]string{"2.16.840.1.113883.6.90", "2.16.840.1.113883.6.3"}))
+		Expect(CODE_SYSTEM_SNOMED.OID()).To(Equal("2.16.840.1.113883.6.96"))
+		Expect(CODE_SYSTEM_MULTUM.OID()).To(Equal(""))
+		Expect(CODE_SYSTEM_MULTUM.OIDs()).To(BeEmpty())
+	})
+
+	It("Round trips every OID", func() {
+		for _, system := range []CodeSystem{CODE_SYSTEM_LOINC, CODE_SYSTEM_CPT, CODE_SYSTEM_NDC, CODE_SYSTEM_CVX, CODE_SYSTEM_ICD9_PROC, CODE_SYSTEM_ICD10_PROC, CODE_SYSTEM_HIPPS} {
+			Expect(LookupOidCodeSystem(system.OID())).To(Equal(system), string(system))
+		}
+	})
+
+	It("Maps code systems to FHIR URIs", func() {
+		Expect(CODE_SYSTEM_ICD10_DIAG.FHIRSystem()).To(Equal("http://hl7.org/fhir/sid/icd-10-cm"))
+		Expect(CODE_SYSTEM_LOINC.FHIRSystem()).To(Equal("http://loinc.org"))
+		Expect(CODE_SYSTEM_UNKNOWN.FHIRSystem()).To(Equal(""))
+	})
+
+	It("Resolves FHIR URIs to code systems", func() {
+		Expect(LookupFhirCodeSystem("http://hl7.org/fhir/sid/icd-10-cm")).To(Equal(CODE_SYSTEM_ICD10_DIAG))
+		Expect(LookupFhirCodeSystem("http://snomed.info/sct")).To(Equal(CODE_SYSTEM_SNOMED))
+		Expect(LookupFhirCodeSystem("https://loinc.org/")).To(Equal(CODE_SYSTEM_LOINC))
+		Expect(LookupFhirCodeSystem("http://www.ama-assn.org/go/cpt")).To(Equal(CODE_SYSTEM_CPT))
+		Expect(LookupFhirCodeSystem("http://hl7.org/fhir/sid/icd-9-cm")).To(Equal(CODE_SYSTEM_ICD9_DIAG))
+		Expect(LookupFhirCodeSystem("http://example.org/codes")).To(Equal(CODE_SYSTEM_UNKNOWN))
+	})
+})
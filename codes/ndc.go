@@ -0,0 +1,223 @@
+package codes
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrNDCInvalidCharacter = errors.New("NDC may only contain digits and hyphens")
+	ErrNDCInvalidLength    = errors.New("NDC must have 10 or 11 digits")
+	ErrNDCInvalidSegments  = errors.New("NDC segments must be 4-4-2, 5-3-2, 5-4-1 or 5-4-2")
+	ErrNDCAmbiguous        = errors.New("Unhyphenated 10 digit NDC is ambiguous without a format")
+	ErrNDCNotConvertible   = errors.New("NDC has no zero-padded segment to remove")
+)
+
+// NDCError reports an NDC that could not be parsed or converted. Err is one of the ErrNDC sentinel errors.
+type NDCError struct {
+	Input string
+	Err   error
+}
+
+func (e *NDCError) Error() string {
+	return fmt.Sprintf("Invalid NDC '%s': %s", e.Input, e.Err.Error())
+}
+
+func (e *NDCError) Unwrap() error {
+	return e.Err
+}
+
+// The segment lengths (labeler-product-package) an NDC was written with
+type NDCFormat string
+
+const (
+	NDC_FORMAT_4_4_2 NDCFormat = "4-4-2"
+	NDC_FORMAT_5_3_2 NDCFormat = "5-3-2"
+	NDC_FORMAT_5_4_1 NDCFormat = "5-4-1"
+	NDC_FORMAT_5_4_2 NDCFormat = "5-4-2" // 11 digit HIPAA format
+)
+
+var ndcFormatLengths = map[NDCFormat][3]int{
+	NDC_FORMAT_4_4_2: {4, 4, 2},
+	NDC_FORMAT_5_3_2: {5, 3, 2},
+	NDC_FORMAT_5_4_1: {5, 4, 1},
+	NDC_FORMAT_5_4_2: {5, 4, 2},
+}
+
+// Ten digit formats, in the order they are tried when converting an 11 digit NDC back to 10 digits
+var ndcTenDigitFormats = []NDCFormat{NDC_FORMAT_4_4_2, NDC_FORMAT_5_3_2, NDC_FORMAT_5_4_1}
+
+type NDCSegment string
+
+const (
+	NDC_SEGMENT_NONE    NDCSegment = ""
+	NDC_SEGMENT_LABELER NDCSegment = "LABELER"
+	NDC_SEGMENT_PRODUCT NDCSegment = "PRODUCT"
+	NDC_SEGMENT_PACKAGE NDCSegment = "PACKAGE"
+)
+
+const (
+	ndcHipaaLength      = 11
+	ndcTenDigitLength   = 10
+	ndcSegmentSeparator = "-"
+)
+
+var ndcPaddedSegments = map[NDCFormat]NDCSegment{
+	NDC_FORMAT_4_4_2: NDC_SEGMENT_LABELER,
+	NDC_FORMAT_5_3_2: NDC_SEGMENT_PRODUCT,
+	NDC_FORMAT_5_4_1: NDC_SEGMENT_PACKAGE,
+	NDC_FORMAT_5_4_2: NDC_SEGMENT_NONE,
+}
+
+// A National Drug Code. The segments are always held in their zero-padded 5-4-2 form; Format records how the code
+// was originally written.
+type NDC struct {
+	Labeler string
+	Product string
+	Package string
+	Format  NDCFormat
+}
+
+// ParseNDC parses a hyphenated NDC in any of the 4-4-2, 5-3-2, 5-4-1 or 5-4-2 formats, or an unhyphenated 11 digit
+// NDC. Unhyphenated 10 digit NDCs need ParseNDCWithFormat.
+func ParseNDC(input string) (NDC, error) {
+	code := strings.TrimSpace(input)
+	if err := checkNdcCharacters(input, code); err != nil {
+		return NDC{}, err
+	}
+
+	if strings.Contains(code, ndcSegmentSeparator) {
+		segments := strings.Split(code, ndcSegmentSeparator)
+		if len(segments) == 3 {
+			for format, lengths := range ndcFormatLengths {
+				if len(segments[0]) == lengths[0] && len(segments[1]) == lengths[1] && len(segments[2]) == lengths[2] {
+					return newNdc(segments, format), nil
+				}
+			}
+		}
+		return NDC{}, &NDCError{Input: input, Err: ErrNDCInvalidSegments}
+	}
+
+	switch len(code) {
+	case ndcHipaaLength:
+		return ParseNDCWithFormat(code, NDC_FORMAT_5_4_2)
+	case ndcTenDigitLength:
+		return NDC{}, &NDCError{Input: input, Err: ErrNDCAmbiguous}
+	default:
+		return NDC{}, &NDCError{Input: input, Err: ErrNDCInvalidLength}
+	}
+}
+
+// ParseNDCWithFormat parses an NDC, with or without hyphens, that is known to be written in the given format
+func ParseNDCWithFormat(input string, format NDCFormat) (NDC, error) {
+	code := strings.TrimSpace(input)
+	if err := checkNdcCharacters(input, code); err != nil {
+		return NDC{}, err
+	}
+
+	lengths, ok := ndcFormatLengths[format]
+	if !ok {
+		return NDC{}, &NDCError{Input: input, Err: ErrNDCInvalidSegments}
+	}
+
+	digits := strings.ReplaceAll(code, ndcSegmentSeparator, "")
+	if len(digits) != lengths[0]+lengths[1]+lengths[2] {
+		return NDC{}, &NDCError{Input: input, Err: ErrNDCInvalidLength}
+	}
+
+	segments := []string{
+		digits[:lengths[0]],
+		digits[lengths[0] : lengths[0]+lengths[1]],
+		digits[lengths[0]+lengths[1]:],
+	}
+	if strings.Contains(code, ndcSegmentSeparator) && strings.Join(segments, ndcSegmentSeparator) != code {
+		return NDC{}, &NDCError{Input: input, Err: ErrNDCInvalidSegments}
+	}
+	return newNdc(segments, format), nil
+}
+
+func IsValidNDC(input string) bool {
+	_, err := ParseNDC(input)
+	return err == nil
+}
+
+func checkNdcCharacters(input, code string) error {
+	for _, r := range code {
+		if (r < '0' || r > '9') && string(r) != ndcSegmentSeparator {
+			return &NDCError{Input: input, Err: ErrNDCInvalidCharacter}
+		}
+	}
+	return nil
+}
+
+func newNdc(segments []string, format NDCFormat) NDC {
+	return NDC{
+		Labeler: padNdcSegment(segments[0], 5),
+		Product: padNdcSegment(segments[1], 4),
+		Package: padNdcSegment(segments[2], 2),
+		Format:  format,
+	}
+}
+
+func padNdcSegment(segment string, length int) string {
+	return strings.Repeat("0", length-len(segment)) + segment
+}
+
+// HIPAA returns the 11 digit 5-4-2 form without hyphens
+func (ndc NDC) HIPAA() string {
+	return ndc.Labeler + ndc.Product + ndc.Package
+}
+
+// Hyphenated returns the 11 digit 5-4-2 form with hyphens
+func (ndc NDC) Hyphenated() string {
+	return strings.Join([]string{ndc.Labeler, ndc.Product, ndc.Package}, ndcSegmentSeparator)
+}
+
+func (ndc NDC) String() string {
+	return ndc.Hyphenated()
+}
+
+// PaddedSegment reports which segment gained a leading zero when the NDC was converted to 11 digits
+func (ndc NDC) PaddedSegment() NDCSegment {
+	return ndcPaddedSegments[ndc.Format]
+}
+
+// TenDigit converts the NDC back to its hyphenated 10 digit form. NDCs parsed from 10 digits keep their original
+// format; for 11 digit NDCs the first segment (labeler, product, then package) with a leading zero is shortened.
+func (ndc NDC) TenDigit() (string, error) {
+	format := ndc.Format
+	if format == NDC_FORMAT_5_4_2 || format == "" {
+		format = ""
+		for _, candidate := range ndcTenDigitFormats {
+			segment := ndc.segment(ndcPaddedSegments[candidate])
+			if strings.HasPrefix(segment, "0") {
+				format = candidate
+				break
+			}
+		}
+		if format == "" {
+			return "", &NDCError{Input: ndc.Hyphenated(), Err: ErrNDCNotConvertible}
+		}
+	}
+
+	lengths := ndcFormatLengths[format]
+	return strings.Join([]string{
+		ndc.Labeler[5-lengths[0]:],
+		ndc.Product[4-lengths[1]:],
+		ndc.Package[2-lengths[2]:],
+	}, ndcSegmentSeparator), nil
+}
+
+func (ndc NDC) segment(segment NDCSegment) string {
+	switch segment {
+	case NDC_SEGMENT_LABELER:
+		return ndc.Labeler
+	case NDC_SEGMENT_PRODUCT:
+		return ndc.Product
+	case NDC_SEGMENT_PACKAGE:
+		return ndc.Package
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,103 @@
+package codes
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("NDC", func() {
+
+	Context("Parsing", func() {
+		It("Parses every hyphenated format", func() {
+			for input, format := range map[string]NDCFormat{
+				"0002-3227-30":  NDC_FORMAT_4_4_2,
+				"50090-347-00":  NDC_FORMAT_5_3_2,
+				"50090-3470-1":  NDC_FORMAT_5_4_1,
+				"00002-3227-30": NDC_FORMAT_5_4_2,
+			} {
+				ndc, err := ParseNDC(input)
+				Expect(err).To(BeNil(), input)
+				Expect(ndc.Format).To(Equal(format), input)
+			}
+		})
+
+		It("Parses 11 digit NDCs", func() {
+			ndc, err := ParseNDC("00002322730")
+			Expect(err).To(BeNil())
+			Expect(ndc.Labeler).To(Equal("00002"))
+			Expect(ndc.Product).To(Equal("3227"))
+			Expect(ndc.Package).To(Equal("30"))
+			Expect(ndc.PaddedSegment()).To(Equal(NDC_SEGMENT_NONE))
+		})
+
+		It("Needs a format for unhyphenated 10 digit NDCs", func() {
+			_, err := ParseNDC("0002322730")
+			Expect(errors.Is(err, ErrNDCAmbiguous)).To(BeTrue())
+
+			ndc, err := ParseNDCWithFormat("0002322730", NDC_FORMAT_4_4_2)
+			Expect(err).To(BeNil())
+			Expect(ndc.HIPAA()).To(Equal("00002322730"))
+		})
+
+		It("Rejects hyphens that disagree with the format", func() {
+			_, err := ParseNDCWithFormat("00023-227-30", NDC_FORMAT_4_4_2)
+			Expect(errors.Is(err, ErrNDCInvalidSegments)).To(BeTrue())
+		})
+
+		It("Returns typed errors", func() {
+			_, err := ParseNDC("0002-32A7-30")
+			var ndcErr *NDCError
+			Expect(errors.As(err, &ndcErr)).To(BeTrue())
+			Expect(ndcErr.Input).To(Equal("0002-32A7-30"))
+			Expect(ndcErr.Err).To(Equal(ErrNDCInvalidCharacter))
+
+			_, err = ParseNDC("123-4567-89")
+			Expect(errors.Is(err, ErrNDCInvalidSegments)).To(BeTrue())
+
+			_, err = ParseNDC("123456789")
+			Expect(errors.Is(err, ErrNDCInvalidLength)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("123456789"))
+
+			Expect(IsValidNDC("0002-3227-30")).To(BeTrue())
+			Expect(IsValidNDC("0002-3227")).To(BeFalse())
+		})
+	})
+
+	Context("Conversion", func() {
+		It("Converts to HIPAA form and reports the padded segment", func() {
+			ndc, _ := ParseNDC("0002-3227-30")
+			Expect(ndc.HIPAA()).To(Equal("00002322730"))
+			Expect(ndc.Hyphenated()).To(Equal("00002-3227-30"))
+			Expect(ndc.PaddedSegment()).To(Equal(NDC_SEGMENT_LABELER))
+
+			ndc, _ = ParseNDC("50090-347-00")
+			Expect(ndc.HIPAA()).To(Equal("50090034700"))
+			Expect(ndc.PaddedSegment()).To(Equal(NDC_SEGMENT_PRODUCT))
+
+			ndc, _ = ParseNDC("50090-3470-1")
+			Expect(ndc.HIPAA()).To(Equal("50090347001"))
+			Expect(ndc.PaddedSegment()).To(Equal(NDC_SEGMENT_PACKAGE))
+		})
+
+		It("Converts back to the original 10 digit format", func() {
+			ndc, _ := ParseNDC("50090-347-00")
+			Expect(ndc.TenDigit()).To(Equal("50090-347-00"))
+		})
+
+		It("Converts 11 digit NDCs to 10 digits", func() {
+			ndc, _ := ParseNDC("00002322730")
+			Expect(ndc.TenDigit()).To(Equal("0002-3227-30"))
+
+			ndc, _ = ParseNDC("50090-0347-00")
+			Expect(ndc.TenDigit()).To(Equal("50090-347-00"))
+
+			ndc, _ = ParseNDC("50090-3470-01")
+			Expect(ndc.TenDigit()).To(Equal("50090-3470-1"))
+
+			ndc, _ = ParseNDC("50090-3470-11")
+			_, err := ndc.TenDigit()
+			Expect(errors.Is(err, ErrNDCNotConvertible)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,119 @@
+package codes
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidProcedureCode = errors.New("Invalid procedure code")
+	ErrInvalidModifier      = errors.New("Invalid procedure code modifier")
+)
+
+const (
+	procedureCodeLength = 5
+	modifierLength      = 2
+)
+
+// A CPT or HCPCS procedure code with its modifiers (CODE_SYSTEM_MODIFIER), in the order they were billed
+type Procedure struct {
+	Code      string
+	Modifiers []string
+}
+
+// ParseProcedure splits a procedure as it appears on a claim, such as "99213-25", "27447 RT LT" or "9921325", into
+// its base code and modifiers
+func ParseProcedure(procedure string) (Procedure, error) {
+	fields := strings.FieldsFunc(strings.ToUpper(procedure), func(r rune) bool {
+		return r == '-' || r == ' ' || r == '\t' || r == ','
+	})
+	if len(fields) == 0 || len(fields[0]) < procedureCodeLength {
+		return Procedure{}, ErrInvalidProcedureCode
+	}
+
+	// Modifiers may follow the code without a separator
+	code := fields[0][:procedureCodeLength]
+	modifiers := make([]string, 0, len(fields))
+	for rest := fields[0][procedureCodeLength:]; rest != ""; rest = rest[min(len(rest), modifierLength):] {
+		modifiers = append(modifiers, rest[:min(len(rest), modifierLength)])
+	}
+	modifiers = append(modifiers, fields[1:]...)
+
+	if !isAlphanumeric(code) {
+		return Procedure{}, ErrInvalidProcedureCode
+	}
+	for _, modifier := range modifiers {
+		if len(modifier) != modifierLength || !isAlphanumeric(modifier) {
+			return Procedure{}, ErrInvalidModifier
+		}
+	}
+	return Procedure{Code: code, Modifiers: modifiers}, nil
+}
+
+func isAlphanumeric(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !((s[i] >= 'A' && s[i] <= 'Z') || (s[i] >= '0' && s[i] <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// String writes the procedure with its modifiers separated by hyphens, as in 27447-RT-LT
+func (p Procedure) String() string {
+	return strings.Join(append([]string{p.Code}, p.Modifiers...), "-")
+}
+
+func (p Procedure) HasModifier(modifier string) bool {
+	modifier = strings.ToUpper(strings.TrimSpace(modifier))
+	for _, m := range p.Modifiers {
+		if m == modifier {
+			return true
+		}
+	}
+	return false
+}
+
+// ProcedureMatcher matches procedures by their base code and modifiers, for rules such as "bilateral procedure with
+// modifier 50". A procedure matches when Procedures (if set) includes its code, RequiredModifiers (if set) includes at
+// least one of its modifiers, and ExcludedModifiers (if set) includes none of them. It is a CodeFinder of procedures as
+// they appear on claims; procedures that cannot be parsed never match.
+type ProcedureMatcher struct {
+	Procedures        *CodeList
+	RequiredModifiers *CodeList
+	ExcludedModifiers *CodeList
+}
+
+func (pm *ProcedureMatcher) Matches(procedure Procedure) bool {
+	if pm.Procedures != nil && !pm.Procedures.Includes(procedure.Code) {
+		return false
+	}
+	if pm.RequiredModifiers != nil && !pm.RequiredModifiers.HasAny(procedure.Modifiers...) {
+		return false
+	}
+	return pm.ExcludedModifiers == nil || !pm.ExcludedModifiers.HasAny(procedure.Modifiers...)
+}
+
+// Includes parses a procedure and reports whether it matches
+func (pm *ProcedureMatcher) Includes(procedure string) bool {
+	parsed, err := ParseProcedure(procedure)
+	return err == nil && pm.Matches(parsed)
+}
+
+func (pm *ProcedureMatcher) HasAny(procedures ...string) bool {
+	for _, procedure := range procedures {
+		if pm.Includes(procedure) {
+			return true
+		}
+	}
+	return false
+}
+
+func (pm *ProcedureMatcher) HasAll(procedures ...string) bool {
+	for _, procedure := range procedures {
+		if !pm.Includes(procedure) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package codes
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("Procedure", func() {
+
+	Context("Parsing", func() {
+		It("Splits the base code from its modifiers", func() {
+			for input, expected := range map[string]Procedure{
+				"99213":       {Code: "99213", Modifiers: []string{}},
+				"99213-25":    {Code: "99213", Modifiers: []string{"25"}},
+				"27447 RT LT": {Code: "27447", Modifiers: []string{"RT", "LT"}},
+				"g0101-gy,59": {Code: "G0101", Modifiers: []string{"GY", "59"}},
+				"9921325":     {Code: "99213", Modifiers: []string{"25"}},
+				"27447RTLT":   {Code: "27447", Modifiers: []string{"RT", "LT"}},
+			} {
+				procedure, err := ParseProcedure(input)
+				Expect(err).To(BeNil(), input)
+				Expect(procedure).To(Equal(expected), input)
+			}
+		})
+
+		It("Rejects malformed procedures", func() {
+			for input, expected := range map[string]error{
+				"":            ErrInvalidProcedureCode,
+				"9921":        ErrInvalidProcedureCode,
+				"99.13-25":    ErrInvalidProcedureCode,
+				"99213-2":     ErrInvalidModifier,
+				"992132":      ErrInvalidModifier,
+				"27447 RT L*": ErrInvalidModifier,
+			} {
+				_, err := ParseProcedure(input)
+				Expect(err).To(Equal(expected), input)
+			}
+		})
+
+		It("Writes procedures with hyphens", func() {
+			procedure, _ := ParseProcedure("27447 RT LT")
+			Expect(procedure.String()).To(Equal("27447-RT-LT"))
+			Expect(procedure.HasModifier("lt")).To(BeTrue())
+			Expect(procedure.HasModifier("50")).To(BeFalse())
+		})
+	})
+
+	Context("Matching", func() {
+		bilateral := &ProcedureMatcher{
+			Procedures:        ParseCodeListFor(CODE_SYSTEM_CPT, "27447, 69436"),
+			RequiredModifiers: ParseCodeListFor(CODE_SYSTEM_MODIFIER, "50"),
+		}
+
+		It("Requires a modifier", func() {
+			Expect(bilateral.Includes("27447-50")).To(BeTrue())
+			Expect(bilateral.Includes("27447 RT 50")).To(BeTrue())
+			Expect(bilateral.Includes("27447 RT")).To(BeFalse())
+			Expect(bilateral.Includes("27446-50")).To(BeFalse())
+		})
+
+		It("Excludes modifiers", func() {
+			visits := &ProcedureMatcher{
+				Procedures:        ParseCodeListFor(CODE_SYSTEM_CPT, "99201..99215"),
+				ExcludedModifiers: ParseCodeListFor(CODE_SYSTEM_MODIFIER, "25, 59"),
+			}
+			Expect(visits.Includes("99213")).To(BeTrue())
+			Expect(visits.Includes("99213-GT")).To(BeTrue())
+			Expect(visits.Includes("99213-25")).To(BeFalse())
+		})
+
+		It("Matches any procedure without a procedure list", func() {
+			telehealth := &ProcedureMatcher{RequiredModifiers: ParseCodeListFor(CODE_SYSTEM_MODIFIER, "95, GT")}
+			Expect(telehealth.Includes("99213-95")).To(BeTrue())
+			Expect(telehealth.Includes("90837 GT")).To(BeTrue())
+			Expect(telehealth.Includes("99213")).To(BeFalse())
+		})
+
+		It("Is a CodeFinder", func() {
+			var finder CodeFinder = bilateral
+			Expect(finder.HasAny("27447", "69436-50")).To(BeTrue())
+			Expect(finder.HasAll("27447-50", "not a procedure")).To(BeFalse())
+		})
+	})
+})
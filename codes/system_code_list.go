@@ -0,0 +1,68 @@
+package codes
+
+import "sort"
+
+// A code list per code system. A coded value is only matched by the list for its own code system.
+type SystemCodeList struct {
+	lists map[CodeSystem]*CodeList
+}
+
+// NewSystemCodeList holds each list as a list of the code system it is given for, so lists parsed without one
+// normalize codes as the code system does
+func NewSystemCodeList(lists map[CodeSystem]*CodeList) *SystemCodeList {
+	scl := &SystemCodeList{lists: make(map[CodeSystem]*CodeList, len(lists))}
+	for system, list := range lists {
+		scl.lists[system] = list.forSystem(system)
+	}
+	return scl
+}
+
+// Add merges the list, as a list of the code system, into the list already held for the code system, if there is one
+func (scl *SystemCodeList) Add(system CodeSystem, list *CodeList) *SystemCodeList {
+	list = list.forSystem(system)
+	if existing, ok := scl.lists[system]; ok {
+		list = existing.Merge(list)
+	}
+	scl.lists[system] = list
+	return scl
+}
+
+// CodeList returns the list for the code system, or nil if there is none
+func (scl *SystemCodeList) CodeList(system CodeSystem) *CodeList {
+	return scl.lists[system]
+}
+
+// Systems returns the code systems that have a list, in sorted order
+func (scl *SystemCodeList) Systems() []CodeSystem {
+	systems := make([]CodeSystem, 0, len(scl.lists))
+	for system := range scl.lists {
+		systems = append(systems, system)
+	}
+	sort.Slice(systems, func(i, j int) bool {
+		return systems[i] < systems[j]
+	})
+	return systems
+}
+
+func (scl *SystemCodeList) Includes(value CodedValue) bool {
+	list, ok := scl.lists[value.System]
+	return ok && list.Includes(value.Code)
+}
+
+func (scl *SystemCodeList) HasAny(values ...CodedValue) bool {
+	for _, value := range values {
+		if scl.Includes(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (scl *SystemCodeList) HasAll(values ...CodedValue) bool {
+	for _, value := range values {
+		if !scl.Includes(value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package codes
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("SystemCodeList", func() {
+
+	visits := func() *SystemCodeList {
+		return NewSystemCodeList(map[CodeSystem]*CodeList{
+			CODE_SYSTEM_CPT:     ParseCodeListFor(CODE_SYSTEM_CPT, "99201..99215"),
+			CODE_SYSTEM_REVENUE: ParseCodeListFor(CODE_SYSTEM_REVENUE, "0510..0529"),
+		})
+	}
+
+	It("Matches the code system as well as the code", func() {
+		list := visits()
+		Expect(list.Includes(CodedValue{System: CODE_SYSTEM_CPT, Code: "99213-25"})).To(BeTrue())
+		Expect(list.Includes(CodedValue{System: CODE_SYSTEM_REVENUE, Code: "0510"})).To(BeTrue())
+		Expect(list.Includes(CodedValue{System: CODE_SYSTEM_CPT, Code: "0510"})).To(BeFalse())
+		Expect(list.Includes(CodedValue{System: CODE_SYSTEM_HCPCS, Code: "99213"})).To(BeFalse())
+	})
+
+	It("Is a CodedValueFinder", func() {
+		var finder CodedValueFinder = visits()
+		Expect(finder.HasAny(CodedValue{CODE_SYSTEM_PLACE_OF_SERVICE, "0510"}, CodedValue{CODE_SYSTEM_REVENUE, "0521"})).To(BeTrue())
+		Expect(finder.HasAll(CodedValue{CODE_SYSTEM_CPT, "99201"}, CodedValue{CODE_SYSTEM_CPT, "0521"})).To(BeFalse())
+	})
+
+	It("Merges lists added for the same code system", func() {
+		list := visits().Add(CODE_SYSTEM_CPT, ParseCodeListFor(CODE_SYSTEM_CPT, "99381"))
+		Expect(list.Systems()).To(Equal([]CodeSystem{CODE_SYSTEM_CPT, CODE_SYSTEM_REVENUE}))
+		Expect(list.CodeList(CODE_SYSTEM_CPT).HasAll("99381", "99201")).To(BeTrue())
+		Expect(list.CodeList(CODE_SYSTEM_HCPCS)).To(BeNil())
+	})
+
+	It("Matches lists parsed without a code system as lists of the code system they are held for", func() {
+		list := NewSystemCodeList(map[CodeSystem]*CodeList{CODE_SYSTEM_ICD10_DIAG: ParseCodeList("E11.9")})
+		Expect(list.HasAny(CodedValue{CODE_SYSTEM_ICD10_DIAG, "E119"})).To(BeTrue())
+		Expect(list.CodeList(CODE_SYSTEM_ICD10_DIAG).System()).To(Equal(CODE_SYSTEM_ICD10_DIAG))
+
+		list.Add(CODE_SYSTEM_ICD10_DIAG, ParseCodeList("E10.9")).Add(CODE_SYSTEM_CPT, ParseCodeList("99213"))
+		Expect(list.HasAll(CodedValue{CODE_SYSTEM_ICD10_DIAG, "E109"}, CodedValue{CODE_SYSTEM_CPT, "99213-25"})).To(BeTrue())
+	})
+
+	It("Formats coded values", func() {
+		Expect(CodedValue{CODE_SYSTEM_CPT, "99213"}.String()).To(Equal(string(CODE_SYSTEM_CPT) + ":99213"))
+	})
+})
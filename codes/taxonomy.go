@@ -0,0 +1,253 @@
+package codes
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrMalformedTaxonomyFile = errors.New("Malformed provider taxonomy file")
+)
+
+// A NUCC Health Care Provider Taxonomy code and its place in the taxonomy's three level hierarchy. 207RC0000X is
+// grouping Allopathic & Osteopathic Physicians, classification Internal Medicine and specialization Cardiovascular
+// Disease; codes for a classification as a whole, such as 207R00000X, have no specialization.
+type Taxonomy struct {
+	Code           string
+	Grouping       string
+	Classification string
+	Specialization string
+	DisplayName    string
+	Definition     string
+}
+
+// Name returns the display name, or the classification and specialization when the file has no display names
+func (t Taxonomy) Name() string {
+	if t.DisplayName != "" {
+		return t.DisplayName
+	}
+	if t.Specialization == "" {
+		return t.Classification
+	}
+	return t.Classification + ", " + t.Specialization
+}
+
+// The columns of the NUCC taxonomy CSV
+var taxonomyColumns = map[string][]string{
+	"code":           {"CODE"},
+	"grouping":       {"GROUPING"},
+	"classification": {"CLASSIFICATION"},
+	"specialization": {"SPECIALIZATION"},
+	"displayName":    {"DISPLAYNAME"},
+	"definition":     {"DEFINITION"},
+}
+
+// The taxonomy codes of a NUCC taxonomy file. It is a CodeDictionary of the codes' names.
+type TaxonomyTable struct {
+	taxonomies map[string]Taxonomy
+}
+
+func LoadTaxonomyFile(path string) (*TaxonomyTable, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return LoadTaxonomy(file)
+}
+
+// LoadTaxonomy reads the CSV version of the taxonomy published by NUCC (nucc_taxonomy_*.csv), whose header names the
+// Code, Grouping, Classification and Specialization columns and optionally Definition and Display Name
+func LoadTaxonomy(reader io.Reader) (*TaxonomyTable, error) {
+	records, err := newDelimitedReader(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	columnNames, err := records.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: missing header", ErrMalformedTaxonomyFile)
+	} else if err != nil {
+		return nil, err
+	}
+
+	columns := columnIndexes(columnNames, taxonomyColumns)
+	for _, column := range []string{"code", "grouping", "classification", "specialization"} {
+		if columns[column] < 0 {
+			return nil, fmt.Errorf("%w: header must name the %s column", ErrMalformedTaxonomyFile, column)
+		}
+	}
+
+	table := &TaxonomyTable{taxonomies: make(map[string]Taxonomy)}
+	for row := 1; ; row++ {
+		record, err := records.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		field := func(column string) string {
+			if index := columns[column]; index >= 0 && index < len(record) {
+				return strings.TrimSpace(record[index])
+			}
+			return ""
+		}
+
+		taxonomy := Taxonomy{
+			Code:           strings.ToUpper(field("code")),
+			Grouping:       field("grouping"),
+			Classification: field("classification"),
+			Specialization: field("specialization"),
+			DisplayName:    field("displayName"),
+			Definition:     field("definition"),
+		}
+		if taxonomy.Code == "" && taxonomy.Grouping == "" && taxonomy.Classification == "" {
+			continue
+		}
+		if taxonomy.Code == "" || taxonomy.Grouping == "" || taxonomy.Classification == "" {
+			return nil, fmt.Errorf("%w: row %d is missing the code, grouping or classification", ErrMalformedTaxonomyFile, row)
+		}
+		table.taxonomies[taxonomy.Code] = taxonomy
+	}
+
+	return table, nil
+}
+
+func (t *TaxonomyTable) Lookup(code string) (Taxonomy, bool) {
+	taxonomy, ok := t.taxonomies[NormalizeCode(CODE_SYSTEM_TAAXONOMY, code)]
+	return taxonomy, ok
+}
+
+func (t *TaxonomyTable) Describe(code string) (string, bool) {
+	if taxonomy, ok := t.Lookup(code); ok {
+		return taxonomy.Name(), true
+	}
+	return "", false
+}
+
+// Codes returns the taxonomy codes in sorted order
+func (t *TaxonomyTable) Codes() []string {
+	codes := make([]string, 0, len(t.taxonomies))
+	for code := range t.taxonomies {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Selects taxonomies by their place in the hierarchy. Names are compared without regard to case, and empty fields
+// match anything; a Specialization of TAXONOMY_NO_SPECIALIZATION only matches codes for a classification as a whole.
+type TaxonomySelector struct {
+	Grouping       string
+	Classification string
+	Specialization string
+}
+
+const TAXONOMY_NO_SPECIALIZATION = "-"
+
+func (s TaxonomySelector) Matches(taxonomy Taxonomy) bool {
+	matches := func(selector, name string) bool {
+		return selector == "" || strings.EqualFold(selector, name)
+	}
+	if s.Specialization == TAXONOMY_NO_SPECIALIZATION {
+		if taxonomy.Specialization != "" {
+			return false
+		}
+	} else if !matches(s.Specialization, taxonomy.Specialization) {
+		return false
+	}
+	return matches(s.Grouping, taxonomy.Grouping) && matches(s.Classification, taxonomy.Classification)
+}
+
+// Select returns a list of the taxonomy codes that match any of the selectors. Like any CodeList, it is a CodeFinder
+// of providers' taxonomy codes.
+func (t *TaxonomyTable) Select(selectors ...TaxonomySelector) *CodeList {
+	list := &CodeList{codes: make(map[string]bool), codeRanges: make([]codeRange, 0), system: CODE_SYSTEM_TAAXONOMY}
+	for code, taxonomy := range t.taxonomies {
+		for _, selector := range selectors {
+			if selector.Matches(taxonomy) {
+				list.codes[code] = true
+				break
+			}
+		}
+	}
+	return list
+}
+
+// A group of specialties that are interchangeable for a purpose, such as attributing members to primary care providers
+type SpecialtyFamily string
+
+const (
+	SPECIALTY_FAMILY_PRIMARY_CARE          SpecialtyFamily = "PRIMARY_CARE"
+	SPECIALTY_FAMILY_OBSTETRICS_GYNECOLOGY SpecialtyFamily = "OBSTETRICS_GYNECOLOGY"
+	SPECIALTY_FAMILY_BEHAVIORAL_HEALTH     SpecialtyFamily = "BEHAVIORAL_HEALTH"
+)
+
+const (
+	taxonomyPhysicians         = "Allopathic & Osteopathic Physicians"
+	taxonomyAdvancedPractice   = "Physician Assistants & Advanced Practice Nursing Providers"
+	taxonomyBehavioralHealth   = "Behavioral Health & Social Service Providers"
+	taxonomyAmbulatoryFacility = "Ambulatory Health Care Facilities"
+)
+
+// Physicians are primary care providers only as generalists: family medicine, general practice, internal medicine and
+// pediatrics without a specialization, so neither a sports medicine family physician nor a cardiologist is one
+var specialtyFamilySelectors = map[SpecialtyFamily][]TaxonomySelector{
+	SPECIALTY_FAMILY_PRIMARY_CARE: {
+		{taxonomyPhysicians, "Family Medicine", TAXONOMY_NO_SPECIALIZATION},
+		{taxonomyPhysicians, "General Practice", TAXONOMY_NO_SPECIALIZATION},
+		{taxonomyPhysicians, "Internal Medicine", TAXONOMY_NO_SPECIALIZATION},
+		{taxonomyPhysicians, "Pediatrics", TAXONOMY_NO_SPECIALIZATION},
+		{taxonomyAdvancedPractice, "Nurse Practitioner", "Family"},
+		{taxonomyAdvancedPractice, "Nurse Practitioner", "Primary Care"},
+		{taxonomyAdvancedPractice, "Nurse Practitioner", "Adult Health"},
+		{taxonomyAdvancedPractice, "Nurse Practitioner", "Gerontology"},
+		{taxonomyAdvancedPractice, "Nurse Practitioner", "Pediatrics"},
+		{taxonomyAdvancedPractice, "Physician Assistant", "Medical"},
+		{taxonomyAmbulatoryFacility, "Clinic/Center", "Primary Care"},
+		{taxonomyAmbulatoryFacility, "Clinic/Center", "Federally Qualified Health Center (FQHC)"},
+		{taxonomyAmbulatoryFacility, "Clinic/Center", "Rural Health"},
+	},
+	SPECIALTY_FAMILY_OBSTETRICS_GYNECOLOGY: {
+		{taxonomyPhysicians, "Obstetrics & Gynecology", ""},
+		{taxonomyAdvancedPractice, "Nurse Practitioner", "Obstetrics & Gynecology"},
+		{taxonomyAdvancedPractice, "Nurse Practitioner", "Women's Health"},
+		{taxonomyAdvancedPractice, "Advanced Practice Midwife", ""},
+	},
+	SPECIALTY_FAMILY_BEHAVIORAL_HEALTH: {
+		{taxonomyBehavioralHealth, "", ""},
+		{taxonomyPhysicians, "Psychiatry & Neurology", "Psychiatry"},
+		{taxonomyPhysicians, "Psychiatry & Neurology", "Child & Adolescent Psychiatry"},
+		{taxonomyPhysicians, "Psychiatry & Neurology", "Addiction Psychiatry"},
+		{taxonomyPhysicians, "Psychiatry & Neurology", "Geriatric Psychiatry"},
+		{taxonomyAdvancedPractice, "Nurse Practitioner", "Psych/Mental Health"},
+		{taxonomyAdvancedPractice, "Clinical Nurse Specialist", "Psych/Mental Health"},
+		{taxonomyAmbulatoryFacility, "Clinic/Center", "Mental Health (Including Community Mental Health Center)"},
+	},
+}
+
+// SpecialtyFamilySelectors returns the selectors that define a built in specialty family
+func SpecialtyFamilySelectors(family SpecialtyFamily) ([]TaxonomySelector, bool) {
+	selectors, ok := specialtyFamilySelectors[family]
+	return append([]TaxonomySelector(nil), selectors...), ok
+}
+
+// Family returns a list of the taxonomy codes in a built in specialty family, so that "any primary care" is
+// table.Family(SPECIALTY_FAMILY_PRIMARY_CARE).HasAny(provider.Taxonomies...). Unknown families have no codes.
+func (t *TaxonomyTable) Family(family SpecialtyFamily) *CodeList {
+	return t.Select(specialtyFamilySelectors[family]...)
+}
+
+// Taxonomy codes are ten letters and digits, the last of which is an X
+func validateTaxonomy(code string) error {
+	if len(code) != 10 || !isAlphanumeric(code) || code[9] != 'X' {
+		return ErrInvalidCodeFormat
+	}
+	return nil
+}
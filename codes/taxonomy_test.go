@@ -0,0 +1,98 @@
+package codes
+
+import (
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("Taxonomy", func() {
+
+	// Rows of the NUCC taxonomy CSV, with the definitions shortened
+	file := `Code,Grouping,Classification,Specialization,Definition,Notes,Display Name,Section
+207Q00000X,Allopathic & Osteopathic Physicians,Family Medicine,,Family Medicine is ...,,Family Medicine Physician,Individual
+207QS0010X,Allopathic & Osteopathic Physicians,Family Medicine,Sports Medicine,,,Sports Medicine (Family Medicine) Physician,Individual
+207R00000X,Allopathic & Osteopathic Physicians,Internal Medicine,,,,Internal Medicine Physician,Individual
+207RC0000X,Allopathic & Osteopathic Physicians,Internal Medicine,Cardiovascular Disease,,,Cardiovascular Disease Physician,Individual
+207V00000X,Allopathic & Osteopathic Physicians,Obstetrics & Gynecology,,,,Obstetrics & Gynecology Physician,Individual
+2084P0800X,Allopathic & Osteopathic Physicians,Psychiatry & Neurology,Psychiatry,,,Psychiatry Physician,Individual
+2084N0400X,Allopathic & Osteopathic Physicians,Psychiatry & Neurology,Neurology,,,Neurology Physician,Individual
+101YM0800X,Behavioral Health & Social Service Providers,Counselor,Mental Health,,,Mental Health Counselor,Individual
+363LF0000X,Physician Assistants & Advanced Practice Nursing Providers,Nurse Practitioner,Family,,,Family Nurse Practitioner,Individual
+363LX0001X,Physician Assistants & Advanced Practice Nursing Providers,Nurse Practitioner,Obstetrics & Gynecology,,,Obstetrics & Gynecology Nurse Practitioner,Individual
+261QP2300X,Ambulatory Health Care Facilities,Clinic/Center,Primary Care,,,Primary Care Clinic/Center,Non-Individual
+`
+	var table *TaxonomyTable
+
+	BeforeEach(func() {
+		var err error
+		table, err = LoadTaxonomy(strings.NewReader(file))
+		Expect(err).To(BeNil())
+	})
+
+	It("Exposes the hierarchy of a code", func() {
+		Expect(table.Codes()).To(HaveLen(11))
+
+		taxonomy, ok := table.Lookup("207rc0000x")
+		Expect(ok).To(BeTrue())
+		Expect(taxonomy.Grouping).To(Equal("Allopathic & Osteopathic Physicians"))
+		Expect(taxonomy.Classification).To(Equal("Internal Medicine"))
+		Expect(taxonomy.Specialization).To(Equal("Cardiovascular Disease"))
+
+		taxonomy, _ = table.Lookup("207Q00000X")
+		Expect(taxonomy.Specialization).To(BeEmpty())
+		Expect(taxonomy.Definition).To(Equal("Family Medicine is ..."))
+
+		_, ok = table.Lookup("207X00000X")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("Describes codes by their display names", func() {
+		description, ok := table.Describe("363LF0000X")
+		Expect(ok).To(BeTrue())
+		Expect(description).To(Equal("Family Nurse Practitioner"))
+		Expect(Taxonomy{Classification: "Internal Medicine", Specialization: "Cardiovascular Disease"}.Name()).To(Equal("Internal Medicine, Cardiovascular Disease"))
+	})
+
+	It("Selects codes by grouping, classification and specialization", func() {
+		Expect(table.Select(TaxonomySelector{Classification: "internal medicine"}).String()).To(Equal("207R00000X,207RC0000X"))
+		Expect(table.Select(TaxonomySelector{Classification: "Internal Medicine", Specialization: TAXONOMY_NO_SPECIALIZATION}).String()).To(Equal("207R00000X"))
+		Expect(table.Select(TaxonomySelector{Specialization: "Obstetrics & Gynecology"}).String()).To(Equal("363LX0001X"))
+		Expect(table.Select().String()).To(BeEmpty())
+	})
+
+	It("Matches providers to specialty families", func() {
+		primaryCare := table.Family(SPECIALTY_FAMILY_PRIMARY_CARE)
+		Expect(primaryCare.String()).To(Equal("207Q00000X,207R00000X,261QP2300X,363LF0000X"))
+		Expect(primaryCare.HasAny("207RC0000X", "363lf0000x")).To(BeTrue())
+		Expect(primaryCare.Includes("207RC0000X")).To(BeFalse())
+		Expect(primaryCare.Includes("207QS0010X")).To(BeFalse())
+
+		var finder CodeFinder = table.Family(SPECIALTY_FAMILY_OBSTETRICS_GYNECOLOGY)
+		Expect(finder.HasAll("207V00000X", "363LX0001X")).To(BeTrue())
+
+		Expect(table.Family(SPECIALTY_FAMILY_BEHAVIORAL_HEALTH).String()).To(Equal("101YM0800X,2084P0800X"))
+		Expect(table.Family("UNKNOWN").String()).To(BeEmpty())
+	})
+
+	It("Returns copies of the family selectors", func() {
+		selectors, ok := SpecialtyFamilySelectors(SPECIALTY_FAMILY_PRIMARY_CARE)
+		Expect(ok).To(BeTrue())
+		selectors[0].Classification = "Changed"
+		Expect(specialtyFamilySelectors[SPECIALTY_FAMILY_PRIMARY_CARE][0].Classification).To(Equal("Family Medicine"))
+	})
+
+	It("Requires the hierarchy columns", func() {
+		_, err := LoadTaxonomy(strings.NewReader("Code,Classification\n207Q00000X,Family Medicine\n"))
+		Expect(errors.Is(err, ErrMalformedTaxonomyFile)).To(BeTrue())
+
+		_, err = LoadTaxonomy(strings.NewReader("Code,Grouping,Classification,Specialization\n,Allopathic & Osteopathic Physicians,Family Medicine,\n"))
+		Expect(errors.Is(err, ErrMalformedTaxonomyFile)).To(BeTrue())
+	})
+
+	It("Validates taxonomy codes", func() {
+		Expect(IsValidCode(CODE_SYSTEM_TAAXONOMY, "207q00000x")).To(BeTrue())
+		Expect(IsValidCode(CODE_SYSTEM_TAAXONOMY, "207Q00000")).To(BeFalse())
+	})
+})
@@ -0,0 +1,214 @@
+package codes
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidTypeOfBill = errors.New("Type of bill must be three digits and a frequency code, optionally after a leading zero")
+)
+
+// The last character of a type of bill, saying where the claim falls in the sequence of claims for a stay or episode
+type TOBFrequency string
+
+const (
+	TOB_FREQUENCY_NONPAYMENT              TOBFrequency = "0"
+	TOB_FREQUENCY_ADMIT_THROUGH_DISCHARGE TOBFrequency = "1"
+	TOB_FREQUENCY_INTERIM_FIRST           TOBFrequency = "2"
+	TOB_FREQUENCY_INTERIM_CONTINUING      TOBFrequency = "3"
+	TOB_FREQUENCY_INTERIM_LAST            TOBFrequency = "4"
+	TOB_FREQUENCY_LATE_CHARGE             TOBFrequency = "5"
+	TOB_FREQUENCY_ADJUSTMENT              TOBFrequency = "6"
+	TOB_FREQUENCY_REPLACEMENT             TOBFrequency = "7"
+	TOB_FREQUENCY_VOID                    TOBFrequency = "8"
+	TOB_FREQUENCY_FINAL_EPISODE           TOBFrequency = "9"
+)
+
+// NUBC facility types, the first digit of a three digit type of bill
+var tobFacilityTypes = map[string]string{
+	"1": "Hospital",
+	"2": "Skilled Nursing",
+	"3": "Home Health",
+	"4": "Religious Nonmedical (Hospital)",
+	"6": "Intermediate Care",
+	"7": "Clinic",
+	"8": "Special Facility",
+}
+
+// Classifications of care, which depend on the facility type: clinics and special facilities have their own
+var tobClassifications = map[string]string{
+	"1": "Inpatient (Including Medicare Part A)",
+	"2": "Inpatient (Medicare Part B Only)",
+	"3": "Outpatient",
+	"4": "Other (For Hospital Referenced Diagnostic Services, or Home Health Not Under a Plan of Treatment)",
+	"5": "Intermediate Care - Level I",
+	"6": "Intermediate Care - Level II",
+	"8": "Swing Beds",
+}
+
+var tobClinicClassifications = map[string]string{
+	"1": "Rural Health Clinic (RHC)",
+	"2": "Hospital Based or Independent Renal Dialysis Facility",
+	"3": "Free Standing Provider-Based Federally Qualified Health Center (FQHC)",
+	"4": "Other Rehabilitation Facility (ORF)",
+	"5": "Comprehensive Outpatient Rehabilitation Facility (CORF)",
+	"6": "Community Mental Health Center (CMHC)",
+	"7": "Federally Qualified Health Center (FQHC)",
+	"8": "Licensed Freestanding Emergency Medical Facility",
+	"9": "Other",
+}
+
+var tobSpecialFacilityClassifications = map[string]string{
+	"1": "Hospice (Non-Hospital Based)",
+	"2": "Hospice (Hospital Based)",
+	"3": "Ambulatory Surgical Center Services to Hospital Outpatients",
+	"4": "Free Standing Birthing Center",
+	"5": "Critical Access Hospital",
+	"6": "Residential Facility",
+	"9": "Other",
+}
+
+var tobFrequencies = map[TOBFrequency]string{
+	TOB_FREQUENCY_NONPAYMENT:              "Nonpayment/Zero Claim",
+	TOB_FREQUENCY_ADMIT_THROUGH_DISCHARGE: "Admit Through Discharge Claim",
+	TOB_FREQUENCY_INTERIM_FIRST:           "Interim - First Claim",
+	TOB_FREQUENCY_INTERIM_CONTINUING:      "Interim - Continuing Claim",
+	TOB_FREQUENCY_INTERIM_LAST:            "Interim - Last Claim",
+	TOB_FREQUENCY_LATE_CHARGE:             "Late Charge Only",
+	TOB_FREQUENCY_ADJUSTMENT:              "Adjustment of Prior Claim",
+	TOB_FREQUENCY_REPLACEMENT:             "Replacement of Prior Claim",
+	TOB_FREQUENCY_VOID:                    "Void/Cancel of Prior Claim",
+	TOB_FREQUENCY_FINAL_EPISODE:           "Final Claim for a Home Health PPS Episode",
+	"A":                                   "Admission/Election Notice",
+	"B":                                   "Termination/Revocation Notice",
+	"C":                                   "Change of Provider Notice",
+	"D":                                   "Void/Cancel of Election Notice",
+	"E":                                   "Change of Ownership",
+	"F":                                   "Beneficiary Initiated Adjustment Claim",
+	"G":                                   "CWF Initiated Adjustment Claim",
+	"H":                                   "CMS Initiated Adjustment Claim",
+	"I":                                   "Intermediary Adjustment Claim (Other than QIO or Provider)",
+	"J":                                   "Initiated Adjustment Claim - Other",
+	"K":                                   "OIG Initiated Adjustment Claim",
+	"M":                                   "MSP Initiated Adjustment Claim",
+	"P":                                   "QIO Adjustment Claim",
+	"Q":                                   "Claim Submitted for Reconsideration Outside of Timely Limits",
+}
+
+// A UB-04 type of bill (form locator 4), such as 0111 for a hospital inpatient admit through discharge claim
+type TypeOfBill struct {
+	FacilityType   string
+	Classification string
+	Frequency      TOBFrequency
+}
+
+// ParseTypeOfBill parses a type of bill in its three character form (111) or its four character form with a leading
+// zero (0111). Only the form is checked; Valid reports whether each part is a code NUBC has assigned.
+func ParseTypeOfBill(tob string) (TypeOfBill, error) {
+	tob = strings.ToUpper(strings.TrimSpace(tob))
+	if len(tob) == 4 {
+		if tob[0] != '0' {
+			return TypeOfBill{}, ErrInvalidTypeOfBill
+		}
+		tob = tob[1:]
+	}
+
+	if len(tob) != 3 || !isDigit(tob[0]) || !isDigit(tob[1]) || !isAlphanumeric(tob[2:]) {
+		return TypeOfBill{}, ErrInvalidTypeOfBill
+	}
+	return TypeOfBill{FacilityType: tob[0:1], Classification: tob[1:2], Frequency: TOBFrequency(tob[2:])}, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// IsValidTypeOfBill reports whether the type of bill can be parsed and each of its parts is an assigned code
+func IsValidTypeOfBill(tob string) bool {
+	parsed, err := ParseTypeOfBill(tob)
+	return err == nil && parsed.Valid()
+}
+
+// Valid reports whether the facility type, classification (for that facility type) and frequency are assigned codes
+func (tob TypeOfBill) Valid() bool {
+	return tob.FacilityTypeDescription() != "" && tob.ClassificationDescription() != "" && tob.FrequencyDescription() != ""
+}
+
+// String writes the four character form, with its leading zero
+func (tob TypeOfBill) String() string {
+	return "0" + tob.ThreeDigit()
+}
+
+func (tob TypeOfBill) ThreeDigit() string {
+	return tob.FacilityType + tob.Classification + string(tob.Frequency)
+}
+
+func (tob TypeOfBill) FacilityTypeDescription() string {
+	return tobFacilityTypes[tob.FacilityType]
+}
+
+func (tob TypeOfBill) ClassificationDescription() string {
+	switch tob.FacilityType {
+	case "7":
+		return tobClinicClassifications[tob.Classification]
+	case "8":
+		return tobSpecialFacilityClassifications[tob.Classification]
+	case "":
+		return ""
+	}
+	if _, ok := tobFacilityTypes[tob.FacilityType]; !ok {
+		return ""
+	}
+	return tobClassifications[tob.Classification]
+}
+
+func (tob TypeOfBill) FrequencyDescription() string {
+	return tobFrequencies[tob.Frequency]
+}
+
+// Description joins the descriptions of the parts, as in "Hospital, Inpatient (Including Medicare Part A), Admit
+// Through Discharge Claim". Unassigned parts are left out.
+func (tob TypeOfBill) Description() string {
+	parts := make([]string, 0, 3)
+	for _, description := range []string{tob.FacilityTypeDescription(), tob.ClassificationDescription(), tob.FrequencyDescription()} {
+		if description != "" {
+			parts = append(parts, description)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// IsOriginal reports whether the claim is a first submission rather than a change to a claim already submitted
+func (tob TypeOfBill) IsOriginal() bool {
+	switch tob.Frequency {
+	case TOB_FREQUENCY_NONPAYMENT, TOB_FREQUENCY_ADMIT_THROUGH_DISCHARGE, TOB_FREQUENCY_INTERIM_FIRST,
+		TOB_FREQUENCY_INTERIM_CONTINUING, TOB_FREQUENCY_INTERIM_LAST, TOB_FREQUENCY_LATE_CHARGE, TOB_FREQUENCY_FINAL_EPISODE:
+		return true
+	}
+	return false
+}
+
+// IsReplacement reports whether the claim replaces a prior claim in full
+func (tob TypeOfBill) IsReplacement() bool {
+	return tob.Frequency == TOB_FREQUENCY_REPLACEMENT
+}
+
+// IsVoid reports whether the claim cancels a prior claim
+func (tob TypeOfBill) IsVoid() bool {
+	return tob.Frequency == TOB_FREQUENCY_VOID
+}
+
+// IsAdjustment reports whether the claim adjusts a prior claim, whether by the provider (6) or by a payer or
+// reviewer (F through Q)
+func (tob TypeOfBill) IsAdjustment() bool {
+	return tob.Frequency == TOB_FREQUENCY_ADJUSTMENT || (len(tob.Frequency) == 1 && tob.Frequency >= "F" && tob.Frequency <= "Q" && tob.FrequencyDescription() != "")
+}
+
+// Types of bill are matched in their four character form, so 111 and 0111 are the same
+func normalizeTypeOfBill(code string) string {
+	if tob, err := ParseTypeOfBill(code); err == nil {
+		return tob.String()
+	}
+	return code
+}
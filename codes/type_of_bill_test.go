@@ -0,0 +1,84 @@
+package codes
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("Type of Bill", func() {
+
+	It("Parses three and four character forms", func() {
+		for _, input := range []string{"111", "0111", " 0111 "} {
+			tob, err := ParseTypeOfBill(input)
+			Expect(err).To(BeNil(), input)
+			Expect(tob).To(Equal(TypeOfBill{FacilityType: "1", Classification: "1", Frequency: TOB_FREQUENCY_ADMIT_THROUGH_DISCHARGE}))
+			Expect(tob.String()).To(Equal("0111"))
+			Expect(tob.ThreeDigit()).To(Equal("111"))
+		}
+	})
+
+	It("Parses letter frequencies", func() {
+		tob, err := ParseTypeOfBill("081a")
+		Expect(err).To(BeNil())
+		Expect(tob.Frequency).To(Equal(TOBFrequency("A")))
+		Expect(tob.Description()).To(Equal("Special Facility, Hospice (Non-Hospital Based), Admission/Election Notice"))
+	})
+
+	It("Rejects malformed types of bill", func() {
+		for _, input := range []string{"", "11", "1111", "01111", "1A1", "11-"} {
+			_, err := ParseTypeOfBill(input)
+			Expect(err).To(Equal(ErrInvalidTypeOfBill), input)
+		}
+	})
+
+	It("Decodes each part", func() {
+		tob, _ := ParseTypeOfBill("0137")
+		Expect(tob.FacilityTypeDescription()).To(Equal("Hospital"))
+		Expect(tob.ClassificationDescription()).To(Equal("Outpatient"))
+		Expect(tob.FrequencyDescription()).To(Equal("Replacement of Prior Claim"))
+	})
+
+	It("Decodes classifications by facility type", func() {
+		clinic, _ := ParseTypeOfBill("711")
+		Expect(clinic.ClassificationDescription()).To(Equal("Rural Health Clinic (RHC)"))
+		special, _ := ParseTypeOfBill("851")
+		Expect(special.ClassificationDescription()).To(Equal("Critical Access Hospital"))
+		hospital, _ := ParseTypeOfBill("151")
+		Expect(hospital.ClassificationDescription()).To(Equal("Intermediate Care - Level I"))
+		emergency, _ := ParseTypeOfBill("781")
+		Expect(emergency.ClassificationDescription()).To(Equal("Licensed Freestanding Emergency Medical Facility"))
+		residential, _ := ParseTypeOfBill("861")
+		Expect(residential.ClassificationDescription()).To(Equal("Residential Facility"))
+	})
+
+	It("Checks that each part is assigned", func() {
+		Expect(IsValidTypeOfBill("0111")).To(BeTrue())
+		Expect(IsValidTypeOfBill("0327")).To(BeTrue())
+		Expect(IsValidTypeOfBill("0911")).To(BeFalse())
+		Expect(IsValidTypeOfBill("0171")).To(BeFalse())
+		Expect(IsValidTypeOfBill("0701")).To(BeFalse())
+		Expect(IsValidTypeOfBill("0871")).To(BeFalse())
+		Expect(IsValidTypeOfBill("011L")).To(BeFalse())
+		Expect(IsValidTypeOfBill("junk")).To(BeFalse())
+	})
+
+	It("Classifies frequencies for collapsing claims", func() {
+		for input, expected := range map[string][]bool{ // original, replacement, void, adjustment
+			"0111": {true, false, false, false},
+			"0114": {true, false, false, false},
+			"0117": {false, true, false, false},
+			"0118": {false, false, true, false},
+			"0116": {false, false, false, true},
+			"011G": {false, false, false, true},
+			"081A": {false, false, false, false},
+		} {
+			tob, _ := ParseTypeOfBill(input)
+			Expect([]bool{tob.IsOriginal(), tob.IsReplacement(), tob.IsVoid(), tob.IsAdjustment()}).To(Equal(expected), input)
+		}
+	})
+
+	It("Normalizes types of bill in code lists", func() {
+		list := ParseCodeListFor(CODE_SYSTEM_TYPE_OF_BILL, "111..114, 0131")
+		Expect(list.HasAll("0112", "111", "131")).To(BeTrue())
+		Expect(list.Includes("0117")).To(BeFalse())
+	})
+})
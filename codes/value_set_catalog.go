@@ -0,0 +1,219 @@
+package codes
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrMalformedValueSetFile = errors.New("Malformed value set file")
+)
+
+// The names each value set file column may have, as columnIndexes compares them
+var valueSetColumns = map[string][]string{
+	"name":          {"VALUESETNAME", "VALUESET", "NAME"},
+	"oid":           {"VALUESETOID", "OID"},
+	"version":       {"VALUESETVERSION", "VERSION"},
+	"code":          {"CODE"},
+	"codeSystem":    {"CODESYSTEM"},
+	"codeSystemOid": {"CODESYSTEMOID"},
+}
+
+// A named value set, such as those published in the HEDIS Value Set Directory or by VSAC, with a CodeList
+// per code system.
+type ValueSet struct {
+	Name    string
+	OID     string
+	Version string
+	Codes   map[CodeSystem]*CodeList
+}
+
+// Systems returns the code systems used by the value set, in sorted order
+func (vs *ValueSet) Systems() []CodeSystem {
+	systems := make([]CodeSystem, 0, len(vs.Codes))
+	for system := range vs.Codes {
+		systems = append(systems, system)
+	}
+	sort.Slice(systems, func(i, j int) bool {
+		return systems[i] < systems[j]
+	})
+	return systems
+}
+
+// SystemCodeList returns the value set's codes as a SystemCodeList, matching both code system and code
+func (vs *ValueSet) SystemCodeList() *SystemCodeList {
+	return NewSystemCodeList(vs.Codes)
+}
+
+// A collection of value sets keyed by value set name
+type ValueSetCatalog struct {
+	valueSets map[string]*ValueSet
+}
+
+func LoadValueSetFile(path string) (*ValueSetCatalog, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return LoadValueSets(file)
+}
+
+// LoadValueSets reads a value-set-to-code export in CSV or TSV form. The first line must be a header naming at least
+// the value set name, code and code system (or code system OID) columns; the delimiter is detected from the header.
+func LoadValueSets(reader io.Reader) (*ValueSetCatalog, error) {
+	records, err := newDelimitedReader(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	columnNames, err := records.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: missing header", ErrMalformedValueSetFile)
+	} else if err != nil {
+		return nil, err
+	}
+
+	columns := columnIndexes(columnNames, valueSetColumns)
+	if columns["name"] < 0 || columns["code"] < 0 || (columns["codeSystem"] < 0 && columns["codeSystemOid"] < 0) {
+		return nil, fmt.Errorf("%w: header must name the value set name, code and code system columns", ErrMalformedValueSetFile)
+	}
+
+	catalog := &ValueSetCatalog{valueSets: make(map[string]*ValueSet)}
+	for row := 1; ; row++ {
+		record, err := records.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		field := func(column string) string {
+			index := columns[column]
+			if index < 0 || index >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index])
+		}
+
+		name, code := field("name"), field("code")
+		if name == "" && code == "" {
+			continue
+		}
+		if name == "" || code == "" {
+			return nil, fmt.Errorf("%w: row %d is missing the value set name or code", ErrMalformedValueSetFile, row)
+		}
+
+		valueSet, ok := catalog.valueSets[name]
+		if !ok {
+			valueSet = &ValueSet{Name: name, Codes: make(map[CodeSystem]*CodeList)}
+			catalog.valueSets[name] = valueSet
+		}
+		if valueSet.OID == "" {
+			valueSet.OID = field("oid")
+		}
+		if valueSet.Version == "" {
+			valueSet.Version = field("version")
+		}
+
+		system := resolveValueSetCodeSystem(field("codeSystem"), field("codeSystemOid"))
+		list, ok := valueSet.Codes[system]
+		if !ok {
+			list = &CodeList{codes: make(map[string]bool), codeRanges: make([]codeRange, 0), system: system}
+			valueSet.Codes[system] = list
+		}
+		list.codes[NormalizeCode(system, code)] = true
+	}
+
+	return catalog, nil
+}
+
+// Reads CSV or TSV, choosing the delimiter from the header line and dropping any byte order mark
+func newDelimitedReader(reader io.Reader) (*csv.Reader, error) {
+	buffered := bufio.NewReader(reader)
+	header, err := buffered.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	header = strings.TrimPrefix(header, "\ufeff")
+
+	records := csv.NewReader(io.MultiReader(strings.NewReader(header), buffered))
+	if strings.Contains(header, "\t") {
+		records.Comma = '\t'
+	}
+	records.FieldsPerRecord = -1
+	records.LazyQuotes = true
+	return records, nil
+}
+
+// Finds the index of each column from the names it may have in the header, or -1 when it has none of them. Header
+// cells are compared as codeSystemKey writes them, without case, spaces or punctuation, so "Value Set OID" is
+// VALUESETOID; names are tried in order and the first one in the header wins.
+func columnIndexes(header []string, columnNames map[string][]string) map[string]int {
+	keys := make([]string, len(header))
+	for index, name := range header {
+		keys[index] = codeSystemKey(name)
+	}
+
+	columns := make(map[string]int, len(columnNames))
+	for column, names := range columnNames {
+		columns[column] = -1
+	names:
+		for _, name := range names {
+			for index, key := range keys {
+				if key == name {
+					columns[column] = index
+					break names
+				}
+			}
+		}
+	}
+	return columns
+}
+
+// Resolves the code system by name, then by OID. Unrecognized names are kept as their own CodeSystem so that codes
+// from different unrecognized systems are not mixed together.
+func resolveValueSetCodeSystem(name, oid string) CodeSystem {
+	if system := ParseCodeSystem(name); system != CODE_SYSTEM_UNKNOWN {
+		return system
+	}
+	if system := LookupOidCodeSystem(oid); system != CODE_SYSTEM_UNKNOWN {
+		return system
+	}
+	if name != "" {
+		return CodeSystem(strings.ToUpper(name))
+	}
+	return CODE_SYSTEM_UNKNOWN
+}
+
+// Names returns the value set names in sorted order
+func (c *ValueSetCatalog) Names() []string {
+	names := make([]string, 0, len(c.valueSets))
+	for name := range c.valueSets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *ValueSetCatalog) ValueSet(name string) (*ValueSet, bool) {
+	valueSet, ok := c.valueSets[name]
+	return valueSet, ok
+}
+
+// CodeList returns the codes of the named value set that belong to the given code system
+func (c *ValueSetCatalog) CodeList(name string, system CodeSystem) (*CodeList, bool) {
+	valueSet, ok := c.valueSets[name]
+	if !ok {
+		return nil, false
+	}
+	list, ok := valueSet.Codes[system]
+	return list, ok
+}
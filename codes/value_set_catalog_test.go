@@ -0,0 +1,115 @@
+package codes
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("ValueSetCatalog", func() {
+
+	Context("CSV", func() {
+		file := `Value Set Name,Value Set OID,Value Set Version,Code,Definition,Code System,Code System OID,Code System Version
+Diabetes,2.16.840.1.113883.3.464.1004.1077,2019-01-01,E11.9,Type 2 diabetes mellitus without complications,ICD10CM,2.16.840.1.113883.6.90,2019
+Diabetes,2.16.840.1.113883.3.464.1004.1077,2019-01-01,e10.9,Type 1 diabetes mellitus without complications,ICD10CM,2.16.840.1.113883.6.90,2019
+Diabetes,2.16.840.1.113883.3.464.1004.1077,2019-01-01,44054006,Diabetes mellitus type 2,SNOMED CT US Edition,2.16.840.1.113883.6.96,2018-09
+Outpatient,2.16.840.1.113883.3.464.1004.1202,2019-01-01,99201,"Office visit, new patient",CPT,2.16.840.1.113883.6.12,2019
+Outpatient,2.16.840.1.113883.3.464.1004.1202,2019-01-01,0510,Clinic,UBREV,2.16.840.1.113883.6.301.3,2019
+`
+		var catalog *ValueSetCatalog
+
+		BeforeEach(func() {
+			var err error
+			catalog, err = LoadValueSets(strings.NewReader(file))
+			Expect(err).To(BeNil())
+		})
+
+		It("Records value set details", func() {
+			Expect(catalog.Names()).To(Equal([]string{"Diabetes", "Outpatient"}))
+
+			vs, ok := catalog.ValueSet("Diabetes")
+			Expect(ok).To(BeTrue())
+			Expect(vs.OID).To(Equal("2.16.840.1.113883.3.464.1004.1077"))
+			Expect(vs.Version).To(Equal("2019-01-01"))
+			Expect(vs.Systems()).To(Equal([]CodeSystem{CODE_SYSTEM_ICD10_DIAG, CODE_SYSTEM_SNOMED}))
+		})
+
+		It("Matches coded values against the code system they belong to", func() {
+			vs, _ := catalog.ValueSet("Diabetes")
+			list := vs.SystemCodeList()
+			Expect(list.HasAny(CodedValue{CODE_SYSTEM_ICD10_DIAG, "E11.9"})).To(BeTrue())
+			Expect(list.HasAny(CodedValue{CODE_SYSTEM_ICD10_DIAG, "44054006"})).To(BeFalse())
+		})
+
+		It("Keys code lists by value set name and code system", func() {
+			icd, ok := catalog.CodeList("Diabetes", CODE_SYSTEM_ICD10_DIAG)
+			Expect(ok).To(BeTrue())
+			Expect(icd.String()).To(Equal("E109,E119"))
+			Expect(icd.Includes("E11.9")).To(BeTrue())
+			Expect(icd.Includes("44054006")).To(BeFalse())
+
+			snomed, ok := catalog.CodeList("Diabetes", CODE_SYSTEM_SNOMED)
+			Expect(ok).To(BeTrue())
+			Expect(snomed.Includes("44054006")).To(BeTrue())
+
+			rev, ok := catalog.CodeList("Outpatient", CODE_SYSTEM_REVENUE)
+			Expect(ok).To(BeTrue())
+			Expect(rev.Includes("0510")).To(BeTrue())
+		})
+
+		It("Reports missing lists", func() {
+			_, ok := catalog.CodeList("Diabetes", CODE_SYSTEM_CPT)
+			Expect(ok).To(BeFalse())
+			_, ok = catalog.CodeList("Hypertension", CODE_SYSTEM_ICD10_DIAG)
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Context("TSV", func() {
+		It("Detects tabs and resolves code systems by OID", func() {
+			file := "Value Set Name\tCode\tCode System OID\nVaccines\t140\t2.16.840.1.113883.12.292\nVaccines\t141\t2.16.840.1.113883.12.292\n"
+			catalog, err := LoadValueSets(strings.NewReader(file))
+			Expect(err).To(BeNil())
+
+			cvx, ok := catalog.CodeList("Vaccines", CODE_SYSTEM_CVX)
+			Expect(ok).To(BeTrue())
+			Expect(cvx.HasAll("140", "141")).To(BeTrue())
+		})
+	})
+
+	Context("Code systems", func() {
+		It("Keeps unrecognized code systems separate", func() {
+			file := "Value Set Name,Code,Code System\nDental,D0120,CDT\n"
+			catalog, err := LoadValueSets(strings.NewReader(file))
+			Expect(err).To(BeNil())
+
+			cdt, ok := catalog.CodeList("Dental", CodeSystem("CDT"))
+			Expect(ok).To(BeTrue())
+			Expect(cdt.Includes("D0120")).To(BeTrue())
+		})
+
+		It("Parses code system names", func() {
+			Expect(ParseCodeSystem("ICD10CM")).To(Equal(CODE_SYSTEM_ICD10_DIAG))
+			Expect(ParseCodeSystem("icd-10-cm")).To(Equal(CODE_SYSTEM_ICD10_DIAG))
+			Expect(ParseCodeSystem("CPT-CAT-II")).To(Equal(CODE_SYSTEM_CPT2))
+			Expect(ParseCodeSystem("National Drug Code")).To(Equal(CODE_SYSTEM_NDC))
+			Expect(ParseCodeSystem("bogus")).To(Equal(CODE_SYSTEM_UNKNOWN))
+			Expect(ParseCodeSystem("")).To(Equal(CODE_SYSTEM_UNKNOWN))
+		})
+	})
+
+	Context("Bad files", func() {
+		It("Requires a usable header", func() {
+			_, err := LoadValueSets(strings.NewReader("Foo,Bar\n1,2\n"))
+			Expect(err).To(MatchError(ErrMalformedValueSetFile))
+
+			_, err = LoadValueSets(strings.NewReader(""))
+			Expect(err).To(MatchError(ErrMalformedValueSetFile))
+		})
+
+		It("Rejects rows without codes", func() {
+			_, err := LoadValueSets(strings.NewReader("Value Set Name,Code,Code System\nDiabetes,,ICD10CM\n"))
+			Expect(err).To(MatchError(ErrMalformedValueSetFile))
+		})
+	})
+})